@@ -0,0 +1,90 @@
+package orm
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/json"
+	"io/ioutil"
+	"strconv"
+
+	"github.com/tinylib/msgp/msgp"
+)
+
+const cacheCodecMsgpack byte = 1
+const cacheCodecMsgpackFlate byte = 2
+
+type cacheCodecConfig struct {
+	compressThreshold int
+}
+
+func encodeRedisValue(engine *Engine, value []string) string {
+	codec := engine.registry.cacheCodec
+	if codec == nil {
+		encoded, _ := json.Marshal(value)
+		return string(encoded)
+	}
+	encoded := make([]byte, 1, 8+len(value)*8)
+	encoded[0] = cacheCodecMsgpack
+	encoded = msgp.AppendArrayHeader(encoded, uint32(len(value)))
+	for _, v := range value {
+		asInt, err := strconv.ParseInt(v, 10, 64)
+		if err == nil && strconv.FormatInt(asInt, 10) == v {
+			encoded = msgp.AppendInt64(encoded, asInt)
+		} else {
+			encoded = msgp.AppendString(encoded, v)
+		}
+	}
+	if codec.compressThreshold > 0 && len(encoded) >= codec.compressThreshold {
+		var buffer bytes.Buffer
+		buffer.WriteByte(cacheCodecMsgpackFlate)
+		writer, _ := flate.NewWriter(&buffer, flate.BestSpeed)
+		_, _ = writer.Write(encoded[1:])
+		_ = writer.Close()
+		if buffer.Len() < len(encoded) {
+			return buffer.String()
+		}
+	}
+	return string(encoded)
+}
+
+func decodeRedisValue(value string) []string {
+	if len(value) == 0 {
+		return nil
+	}
+	switch value[0] {
+	case cacheCodecMsgpack:
+		return decodeMsgpackRedisValue([]byte(value[1:]))
+	case cacheCodecMsgpackFlate:
+		reader := flate.NewReader(bytes.NewReader([]byte(value[1:])))
+		defer reader.Close()
+		decompressed, err := ioutil.ReadAll(reader)
+		if err != nil {
+			return nil
+		}
+		return decodeMsgpackRedisValue(decompressed)
+	}
+	var decoded []string
+	_ = json.Unmarshal([]byte(value), &decoded)
+	return decoded
+}
+
+func decodeMsgpackRedisValue(encoded []byte) []string {
+	size, encoded, err := msgp.ReadArrayHeaderBytes(encoded)
+	if err != nil {
+		return nil
+	}
+	decoded := make([]string, size)
+	for i := range decoded {
+		if msgp.NextType(encoded) == msgp.StrType {
+			decoded[i], encoded, err = msgp.ReadStringBytes(encoded)
+		} else {
+			var asInt int64
+			asInt, encoded, err = msgp.ReadInt64Bytes(encoded)
+			decoded[i] = strconv.FormatInt(asInt, 10)
+		}
+		if err != nil {
+			return nil
+		}
+	}
+	return decoded
+}
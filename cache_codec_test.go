@@ -0,0 +1,69 @@
+package orm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testEntityCacheCodec struct {
+	ORM  `orm:"redisCache"`
+	ID   uint
+	Name string `orm:"length=max"`
+	Age  int
+}
+
+func TestCacheCodec(t *testing.T) {
+	value := []string{"name", "12", "-7", "012", "1.5", "", "99999999999999999999"}
+	engine := &Engine{registry: &validatedRegistry{}}
+	asJSON := encodeRedisValue(engine, value)
+	assert.Equal(t, `["name","12","-7","012","1.5","","99999999999999999999"]`, asJSON)
+	assert.Equal(t, value, decodeRedisValue(asJSON))
+
+	engine.registry.cacheCodec = &cacheCodecConfig{}
+	encoded := encodeRedisValue(engine, value)
+	assert.Equal(t, cacheCodecMsgpack, encoded[0])
+	assert.True(t, len(encoded) < len(asJSON))
+	assert.Equal(t, value, decodeRedisValue(encoded))
+
+	engine.registry.cacheCodec = &cacheCodecConfig{compressThreshold: 100}
+	value = append(value, strings.Repeat("long text ", 100))
+	encoded = encodeRedisValue(engine, value)
+	assert.Equal(t, cacheCodecMsgpackFlate, encoded[0])
+	assert.True(t, len(encoded) < 200)
+	assert.Equal(t, value, decodeRedisValue(encoded))
+	assert.Nil(t, decodeRedisValue(""))
+}
+
+func TestCacheCodecLoadByID(t *testing.T) {
+	var entity testEntityCacheCodec
+	registry := &Registry{}
+	registry.RegisterMsgpackCacheCodec(100)
+	engine := PrepareTables(t, registry, entity)
+	engine.TrackAndFlush(&testEntityCacheCodec{Name: strings.Repeat("a", 200), Age: 18})
+	engine.TrackAndFlush(&testEntityCacheCodec{Name: "b", Age: 20})
+
+	var rows []*testEntityCacheCodec
+	engine.LoadByIDs([]uint64{1, 2}, &rows)
+	assert.Len(t, rows, 2)
+	cacheKey := engine.registry.GetTableSchemaForEntity(&entity).(*tableSchema).getCacheKey(1)
+	cached, has := engine.GetRedis().Get(cacheKey)
+	assert.True(t, has)
+	assert.Equal(t, cacheCodecMsgpackFlate, cached[0])
+
+	found := engine.LoadByID(1, &entity)
+	assert.True(t, found)
+	assert.Equal(t, strings.Repeat("a", 200), entity.Name)
+	assert.Equal(t, 18, entity.Age)
+	found = engine.LoadByID(2, &entity)
+	assert.True(t, found)
+	assert.Equal(t, "b", entity.Name)
+	assert.Equal(t, 20, entity.Age)
+
+	engine.GetRedis().Set(cacheKey, `["json","30"]`, 0)
+	found = engine.LoadByID(1, &entity)
+	assert.True(t, found)
+	assert.Equal(t, "json", entity.Name)
+	assert.Equal(t, 30, entity.Age)
+}
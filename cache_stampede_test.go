@@ -0,0 +1,130 @@
+package orm
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	log2 "github.com/apex/log"
+
+	"github.com/apex/log/handlers/memory"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testEntityCacheStampede struct {
+	ORM  `orm:"redisCache"`
+	ID   uint
+	Name string
+}
+
+func TestSingleFlight(t *testing.T) {
+	group := &singleFlight{}
+	calls := int32(0)
+	start := make(chan bool)
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			value, _ := group.do("key", func() interface{} {
+				atomic.AddInt32(&calls, 1)
+				time.Sleep(time.Millisecond * 100)
+				return "value"
+			})
+			assert.Equal(t, "value", value)
+		}()
+	}
+	close(start)
+	wg.Wait()
+	assert.Equal(t, int32(1), calls)
+
+	assert.PanicsWithValue(t, "error", func() {
+		group.do("key", func() interface{} {
+			panic("error")
+		})
+	})
+	value, shared := group.do("key", func() interface{} {
+		return "value 2"
+	})
+	assert.Equal(t, "value 2", value)
+	assert.False(t, shared)
+}
+
+func TestLoadByIDCacheStampede(t *testing.T) {
+	var entity testEntityCacheStampede
+	registry := &Registry{}
+	registry.RegisterCacheStampedeLock(time.Second)
+	engine := PrepareTables(t, registry, entity)
+	engine.TrackAndFlush(&testEntityCacheStampede{Name: "Name 1"})
+	engine.GetRedis().FlushDB()
+
+	DBLogger := memory.New()
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		e := engine.GetRegistry().CreateEngine()
+		e.AddQueryLogger(DBLogger, log2.InfoLevel, QueryLoggerSourceDB)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var row testEntityCacheStampede
+			found := e.LoadByID(1, &row)
+			assert.True(t, found)
+			assert.Equal(t, "Name 1", row.Name)
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, DBLogger.Entries, 1)
+
+	redisCache := engine.GetRedis()
+	calls := 0
+	for i := 0; i < 2; i++ {
+		val := redisCache.GetSet("stampede", 10, func() interface{} {
+			calls++
+			return "hello"
+		})
+		assert.Equal(t, "hello", val)
+	}
+	assert.Equal(t, 1, calls)
+	_, has := redisCache.Get("stampede:lock")
+	assert.False(t, has)
+}
+
+type testEntityCacheStampedeLocal struct {
+	ORM  `orm:"localCache;redisCache"`
+	ID   uint
+	Name string
+}
+
+func TestLoadByIDCacheStampedeFillsLocalCache(t *testing.T) {
+	var entity testEntityCacheStampedeLocal
+	registry := &Registry{}
+	registry.RegisterCacheStampedeLock(time.Second)
+	engine := PrepareTables(t, registry, entity)
+	engine.TrackAndFlush(&testEntityCacheStampedeLocal{Name: "Name 1"})
+	schema := engine.GetRegistry().GetTableSchemaForEntity(&entity).(*tableSchema)
+	localCache, _ := schema.GetLocalCache(engine)
+	redisCache, _ := schema.GetRedisCache(engine)
+	cacheKey := schema.getCacheKey(1)
+	found := engine.LoadByID(1, &entity)
+	assert.True(t, found)
+	redisCache.Set(cacheKey, buildRedisValue(engine, &entity), 0)
+	localCache.Clear()
+
+	DBLogger := memory.New()
+	engine.AddQueryLogger(DBLogger, log2.InfoLevel, QueryLoggerSourceDB)
+	row := loadByIDFromDB(engine, 1, schema, cacheKey, localCache, redisCache)
+	assert.Equal(t, "Name 1", row[0])
+	assert.Len(t, DBLogger.Entries, 0)
+	cached, has := localCache.Get(cacheKey)
+	assert.True(t, has)
+	assert.Equal(t, row, cached)
+
+	redisCache.Set(schema.getCacheKey(2), "nil", 10)
+	assert.Nil(t, loadByIDFromDB(engine, 2, schema, schema.getCacheKey(2), localCache, redisCache))
+	cached, has = localCache.Get(schema.getCacheKey(2))
+	assert.True(t, has)
+	assert.Equal(t, "nil", cached)
+}
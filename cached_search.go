@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -40,7 +41,7 @@ func cachedSearch(engine *Engine, entities interface{}, indexName string, pager
 	if !hasLocalCache && !hasRedis {
 		panic(errors.NotValidf("cache search not allowed for entity without cache: '%s'", entityType.String()))
 	}
-	cacheKey := getCacheKeySearch(schema, indexName, Where.GetParameters()...)
+	cacheKey, pagePrefix := getCacheKeySearchForDefinition(schema, indexName, definition, Where, arguments)
 
 	minCachePage := float64((pager.GetCurrentPage() - 1) * pager.GetPageSize() / idsOnCachePage)
 	minCachePageCeil := minCachePage
@@ -49,7 +50,7 @@ func cachedSearch(engine *Engine, entities interface{}, indexName string, pager
 	pages := make([]string, 0)
 	filledPages := make(map[string][]uint64)
 	for i := minCachePageCeil; i < maxCachePageCeil; i++ {
-		pages = append(pages, strconv.Itoa(int(i)+1))
+		pages = append(pages, pagePrefix+strconv.Itoa(int(i)+1))
 	}
 	var fromCache map[string]interface{}
 	var nilsKeys []string
@@ -82,7 +83,7 @@ func cachedSearch(engine *Engine, entities interface{}, indexName string, pager
 	for key, idsAsString := range fromCache {
 		if idsAsString == nil {
 			hasNil = true
-			p, _ := strconv.Atoi(key)
+			p, _ := strconv.Atoi(key[len(pagePrefix):])
 			if p < minPage {
 				minPage = p
 			}
@@ -102,15 +103,15 @@ func cachedSearch(engine *Engine, entities interface{}, indexName string, pager
 	}
 
 	if hasNil {
-		searchPager := &Pager{minPage, maxPage * idsOnCachePage}
+		searchPager := &Pager{CurrentPage: 1, PageSize: maxPage * idsOnCachePage}
 		results, total := searchIDsWithCount(true, engine, Where, searchPager, entityType)
 		totalRows = total
 		cacheFields := make(map[string]interface{})
 		for key, ids := range fromCache {
 			if ids == nil {
 				page := key
-				pageInt, _ := strconv.Atoi(page)
-				sliceStart := (pageInt - minPage) * idsOnCachePage
+				pageInt, _ := strconv.Atoi(page[len(pagePrefix):])
+				sliceStart := (pageInt - 1) * idsOnCachePage
 				if sliceStart > total {
 					cacheFields[page] = total
 					continue
@@ -148,7 +149,7 @@ func cachedSearch(engine *Engine, entities interface{}, indexName string, pager
 
 	resultsIDs := make([]uint64, 0, len(filledPages)*idsOnCachePage)
 	for i := minCachePageCeil; i < maxCachePageCeil; i++ {
-		resultsIDs = append(resultsIDs, filledPages[strconv.Itoa(int(i)+1)]...)
+		resultsIDs = append(resultsIDs, filledPages[pagePrefix+strconv.Itoa(int(i)+1)]...)
 	}
 	sliceStart := (pager.GetCurrentPage() - 1) * pager.GetPageSize()
 	diff := int(minCachePageCeil) * idsOnCachePage
@@ -186,16 +187,17 @@ func cachedSearchOne(engine *Engine, entity Entity, indexName string, arguments
 	if !hasLocalCache && !hasRedis {
 		panic(errors.NotValidf("cache search not allowed for entity without cache: '%s'", entityType.String()))
 	}
-	cacheKey := getCacheKeySearch(schema, indexName, Where.GetParameters()...)
+	cacheKey, pagePrefix := getCacheKeySearchForDefinition(schema, indexName, definition, Where, arguments)
+	field := pagePrefix + "1"
 	var fromCache map[string]interface{}
 	if hasLocalCache {
-		fromCache = localCache.HMget(cacheKey, "1")
+		fromCache = localCache.HMget(cacheKey, field)
 	}
-	if fromCache["1"] == nil && hasRedis {
-		fromCache = redisCache.HMget(cacheKey, "1")
+	if fromCache[field] == nil && hasRedis {
+		fromCache = redisCache.HMget(cacheKey, field)
 	}
 	var id uint64
-	if fromCache["1"] == nil {
+	if fromCache[field] == nil {
 		results, _ := searchIDs(true, engine, Where, &Pager{CurrentPage: 1, PageSize: 1}, false, entityType)
 		l := len(results)
 		value := fmt.Sprintf("%d", l)
@@ -203,7 +205,7 @@ func cachedSearchOne(engine *Engine, entity Entity, indexName string, arguments
 			id = results[0]
 			value += fmt.Sprintf(" %d", results[0])
 		}
-		fields := map[string]interface{}{"1": value}
+		fields := map[string]interface{}{field: value}
 		if hasLocalCache {
 			localCache.HMset(cacheKey, fields)
 		}
@@ -211,7 +213,7 @@ func cachedSearchOne(engine *Engine, entity Entity, indexName string, arguments
 			redisCache.HMset(cacheKey, fields)
 		}
 	} else {
-		ids := strings.Split(fromCache["1"].(string), " ")
+		ids := strings.Split(fromCache[field].(string), " ")
 		if ids[0] != "0" {
 			id, _ = strconv.ParseUint(ids[1], 10, 64)
 		}
@@ -226,3 +228,265 @@ func getCacheKeySearch(tableSchema *tableSchema, indexName string, parameters ..
 	hash := fnv1a.HashString32(fmt.Sprintf("%v", parameters))
 	return fmt.Sprintf("%s_%s_%d", tableSchema.cachePrefix, indexName, hash)
 }
+
+func getCacheKeySearchForDefinition(tableSchema *tableSchema, indexName string, definition *cachedQueryDefinition,
+	where *Where, arguments []interface{}) (cacheKey string, pagePrefix string) {
+	if !definition.Ranges {
+		return getCacheKeySearch(tableSchema, indexName, where.GetParameters()...), ""
+	}
+	bucket := make([]interface{}, len(definition.BucketArguments))
+	for i, position := range definition.BucketArguments {
+		if position < len(arguments) {
+			bucket[i] = arguments[position]
+		}
+	}
+	hash := fnv1a.HashString32(fmt.Sprintf("%v", where.GetParameters()))
+	return getCacheKeySearch(tableSchema, indexName, bucket...), strconv.FormatUint(uint64(hash), 10) + ":"
+}
+
+const cachedSearchPatchScript = `
+local fields = redis.call('HGETALL', KEYS[1])
+if #fields == 0 then
+	return 0
+end
+local pages = {}
+local maxPage = 0
+for i = 1, #fields, 2 do
+	local page = tonumber(fields[i])
+	if page == nil or page < 1 then
+		redis.call('DEL', KEYS[1])
+		return 0
+	end
+	pages[page] = fields[i + 1]
+	if page > maxPage then
+		maxPage = page
+	end
+end
+local total = nil
+local ids = {}
+for page = 1, maxPage do
+	local value = pages[page]
+	if value == nil then
+		redis.call('DEL', KEYS[1])
+		return 0
+	end
+	local first = true
+	for part in string.gmatch(value, '%d+') do
+		if first then
+			if total ~= nil and total ~= part then
+				redis.call('DEL', KEYS[1])
+				return 0
+			end
+			total = part
+			first = false
+		else
+			ids[#ids + 1] = part
+		end
+	end
+end
+if total == nil or tonumber(total) ~= #ids then
+	redis.call('DEL', KEYS[1])
+	return 0
+end
+local max = tonumber(ARGV[1])
+local size = tonumber(ARGV[2])
+for i = 3, #ARGV do
+	local id = string.sub(ARGV[i], 2)
+	local idNumber = tonumber(id)
+	local position = #ids + 1
+	local exists = false
+	for j = 1, #ids do
+		local current = tonumber(ids[j])
+		if current >= idNumber then
+			position = j
+			exists = current == idNumber
+			break
+		end
+	end
+	if string.sub(ARGV[i], 1, 1) == '+' then
+		if not exists then
+			if #ids >= max then
+				redis.call('DEL', KEYS[1])
+				return 0
+			end
+			table.insert(ids, position, id)
+		end
+	elseif exists then
+		table.remove(ids, position)
+	end
+end
+local pagesCount = math.ceil(#ids / size)
+if pagesCount < maxPage then
+	pagesCount = maxPage
+end
+redis.call('DEL', KEYS[1])
+for page = 1, pagesCount do
+	local values = {tostring(#ids)}
+	for j = (page - 1) * size + 1, math.min(page * size, #ids) do
+		values[#values + 1] = ids[j]
+	end
+	redis.call('HSET', KEYS[1], tostring(page), table.concat(values, ' '))
+end
+return 1
+`
+
+type cachedQueryPatch struct {
+	max        int
+	operations []interface{}
+}
+
+func addCacheQueriesPatches(patches map[string]map[string]*cachedQueryPatch, cacheCode string, schema *tableSchema,
+	bind map[string]interface{}, data map[string]interface{}, old map[string]interface{}, id uint64) {
+	if patches == nil {
+		return
+	}
+	for indexName, definition := range schema.cachedIndexes {
+		if !definition.Patchable {
+			continue
+		}
+		if data != nil && old != nil {
+			changed := false
+			for _, trackedField := range definition.TrackedFields {
+				_, changed = bind[trackedField]
+				if changed {
+					break
+				}
+			}
+			if !changed {
+				continue
+			}
+		}
+		oldKey := ""
+		newKey := ""
+		if old != nil {
+			oldKey = getCacheKeySearch(schema, indexName, getCachedQueryAttributes(definition, old)...)
+		}
+		if data != nil {
+			newKey = getCacheKeySearch(schema, indexName, getCachedQueryAttributes(definition, data)...)
+		}
+		if oldKey == newKey {
+			continue
+		}
+		if patches[cacheCode] == nil {
+			patches[cacheCode] = make(map[string]*cachedQueryPatch)
+		}
+		if oldKey != "" {
+			addCacheQueryPatch(patches[cacheCode], oldKey, definition.Max, "-"+strconv.FormatUint(id, 10))
+		}
+		if newKey != "" {
+			addCacheQueryPatch(patches[cacheCode], newKey, definition.Max, "+"+strconv.FormatUint(id, 10))
+		}
+	}
+}
+
+func addCacheQueryPatch(patches map[string]*cachedQueryPatch, key string, max int, operation string) {
+	patch, has := patches[key]
+	if !has {
+		patch = &cachedQueryPatch{max: max}
+		patches[key] = patch
+	}
+	patch.operations = append(patch.operations, operation)
+}
+
+func getCachedQueryAttributes(definition *cachedQueryDefinition, data map[string]interface{}) []interface{} {
+	attributes := make([]interface{}, len(definition.QueryFields))
+	for i, field := range definition.QueryFields {
+		attributes[i] = data[field]
+	}
+	return attributes
+}
+
+func applyCacheQueriesPatchesRedis(cache *RedisCache, patches map[string]*cachedQueryPatch) {
+	for key, patch := range patches {
+		args := append([]interface{}{patch.max, idsOnCachePage}, patch.operations...)
+		cache.Eval(cachedSearchPatchScript, []string{key}, args...)
+	}
+}
+
+func applyCacheQueriesPatchesLocal(cache *LocalCache, patches map[string]*cachedQueryPatch) {
+	for key, patch := range patches {
+		pages := cache.HGetAll(key)
+		if len(pages) == 0 {
+			continue
+		}
+		fields, valid := patchCachedSearchPages(pages, patch)
+		cache.Remove(key)
+		if valid {
+			cache.HMset(key, fields)
+		}
+	}
+}
+
+func patchCachedSearchPages(pages map[string]interface{}, patch *cachedQueryPatch) (fields map[string]interface{}, valid bool) {
+	maxPage := 0
+	for field := range pages {
+		page, err := strconv.Atoi(field)
+		if err != nil || page < 1 {
+			return nil, false
+		}
+		if page > maxPage {
+			maxPage = page
+		}
+	}
+	total := -1
+	ids := make([]uint64, 0)
+	for page := 1; page <= maxPage; page++ {
+		value, has := pages[strconv.Itoa(page)]
+		if !has {
+			return nil, false
+		}
+		parts := strings.Split(fmt.Sprintf("%v", value), " ")
+		pageTotal, err := strconv.Atoi(parts[0])
+		if err != nil || (total >= 0 && pageTotal != total) {
+			return nil, false
+		}
+		total = pageTotal
+		for _, part := range parts[1:] {
+			id, err := strconv.ParseUint(part, 10, 64)
+			if err != nil {
+				return nil, false
+			}
+			ids = append(ids, id)
+		}
+	}
+	if total != len(ids) {
+		return nil, false
+	}
+	for _, operation := range patch.operations {
+		asString := operation.(string)
+		id, _ := strconv.ParseUint(asString[1:], 10, 64)
+		position := sort.Search(len(ids), func(i int) bool { return ids[i] >= id })
+		exists := position < len(ids) && ids[position] == id
+		if asString[0] == '+' {
+			if exists {
+				continue
+			}
+			if len(ids) >= patch.max {
+				return nil, false
+			}
+			ids = append(ids, 0)
+			copy(ids[position+1:], ids[position:])
+			ids[position] = id
+		} else if exists {
+			ids = append(ids[:position], ids[position+1:]...)
+		}
+	}
+	pagesCount := int(math.Ceil(float64(len(ids)) / float64(idsOnCachePage)))
+	if pagesCount < maxPage {
+		pagesCount = maxPage
+	}
+	fields = make(map[string]interface{}, pagesCount)
+	for page := 1; page <= pagesCount; page++ {
+		values := []uint64{uint64(len(ids))}
+		start := (page - 1) * idsOnCachePage
+		if start < len(ids) {
+			end := start + idsOnCachePage
+			if end > len(ids) {
+				end = len(ids)
+			}
+			values = append(values, ids[start:end]...)
+		}
+		fields[strconv.Itoa(page)] = strings.Trim(fmt.Sprintf("%v", values), "[]")
+	}
+	return fields, true
+}
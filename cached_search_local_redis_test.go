@@ -117,18 +117,18 @@ func TestCachedSearchLocalRedis(t *testing.T) {
 	assert.Len(t, rows, 6)
 	assert.Equal(t, uint(1), rows[0].ID)
 	assert.Equal(t, uint(6), rows[1].ID)
-	assert.Len(t, DBLogger.Entries, 3)
+	assert.Len(t, DBLogger.Entries, 2)
 
 	totalRows = engine.CachedSearch(&rows, "IndexAge", pager, 10)
 	assert.Equal(t, 4, totalRows)
 	assert.Len(t, rows, 4)
 	assert.Equal(t, uint(2), rows[0].ID)
-	assert.Len(t, DBLogger.Entries, 4)
+	assert.Len(t, DBLogger.Entries, 2)
 
 	totalRows = engine.CachedSearch(&rows, "IndexAll", pager)
 	assert.Equal(t, 10, totalRows)
 	assert.Len(t, rows, 10)
-	assert.Len(t, DBLogger.Entries, 5)
+	assert.Len(t, DBLogger.Entries, 3)
 
 	engine.MarkToDelete(rows[1])
 	engine.Flush()
@@ -137,12 +137,12 @@ func TestCachedSearchLocalRedis(t *testing.T) {
 	assert.Equal(t, 3, totalRows)
 	assert.Len(t, rows, 3)
 	assert.Equal(t, uint(3), rows[0].ID)
-	assert.Len(t, DBLogger.Entries, 7)
+	assert.Len(t, DBLogger.Entries, 4)
 
 	totalRows = engine.CachedSearch(&rows, "IndexAll", pager)
 	assert.Equal(t, 9, totalRows)
 	assert.Len(t, rows, 9)
-	assert.Len(t, DBLogger.Entries, 8)
+	assert.Len(t, DBLogger.Entries, 4)
 
 	entity = &testEntityIndexTestLocalRedis{Name: "Name 11", Age: uint16(18)}
 	engine.Track(entity)
@@ -152,18 +152,18 @@ func TestCachedSearchLocalRedis(t *testing.T) {
 	assert.Equal(t, 7, totalRows)
 	assert.Len(t, rows, 7)
 	assert.Equal(t, uint(11), rows[6].ID)
-	assert.Len(t, DBLogger.Entries, 10)
+	assert.Len(t, DBLogger.Entries, 5)
 
 	totalRows = engine.CachedSearch(&rows, "IndexAll", pager)
 	assert.Equal(t, 10, totalRows)
 	assert.Len(t, rows, 10)
-	assert.Len(t, DBLogger.Entries, 11)
+	assert.Len(t, DBLogger.Entries, 5)
 
 	engine.ClearByIDs(entity, 1, 3)
 	totalRows = engine.CachedSearch(&rows, "IndexAll", pager)
 	assert.Equal(t, 10, totalRows)
 	assert.Len(t, rows, 10)
-	assert.Len(t, DBLogger.Entries, 12)
+	assert.Len(t, DBLogger.Entries, 6)
 
 	var row testEntityIndexTestLocalRedis
 	has := engine.CachedSearchOne(&row, "IndexName", "Name 6")
@@ -176,7 +176,7 @@ func TestCachedSearchLocalRedis(t *testing.T) {
 	totalRows = engine.CachedSearchWithReferences(&rows, "IndexAll", pager, []interface{}{}, []string{"*"})
 	assert.Equal(t, 10, totalRows)
 	assert.Len(t, rows, 10)
-	assert.Len(t, DBLogger.Entries, 15)
+	assert.Len(t, DBLogger.Entries, 9)
 	assert.Equal(t, "Name 1", rows[0].ReferenceOne.Name)
 	assert.Equal(t, "Name 3", rows[1].ReferenceOne.Name)
 	assert.True(t, engine.Loaded(rows[0].ReferenceOne))
@@ -142,7 +142,7 @@ func TestCachedSearchLocal(t *testing.T) {
 	totalRows = engine.CachedSearch(&rows, "IndexAll", pager)
 	assert.Equal(t, 9, totalRows)
 	assert.Len(t, rows, 9)
-	assert.Len(t, DBLogger.Entries, 8)
+	assert.Len(t, DBLogger.Entries, 7)
 
 	entity = &testEntityIndexTestLocal{Name: "Name 11", Age: uint16(18)}
 	engine.Track(entity)
@@ -152,18 +152,18 @@ func TestCachedSearchLocal(t *testing.T) {
 	assert.Equal(t, 7, totalRows)
 	assert.Len(t, rows, 7)
 	assert.Equal(t, uint(11), rows[6].ID)
-	assert.Len(t, DBLogger.Entries, 10)
+	assert.Len(t, DBLogger.Entries, 9)
 
 	totalRows = engine.CachedSearch(&rows, "IndexAll", pager)
 	assert.Equal(t, 10, totalRows)
 	assert.Len(t, rows, 10)
-	assert.Len(t, DBLogger.Entries, 11)
+	assert.Len(t, DBLogger.Entries, 9)
 
 	engine.ClearByIDs(entity, 1, 3)
 	totalRows = engine.CachedSearch(&rows, "IndexAll", pager)
 	assert.Equal(t, 10, totalRows)
 	assert.Len(t, rows, 10)
-	assert.Len(t, DBLogger.Entries, 12)
+	assert.Len(t, DBLogger.Entries, 10)
 
 	var row testEntityIndexTestLocal
 	has := engine.CachedSearchOne(&row, "IndexName", "Name 6")
@@ -184,6 +184,20 @@ func TestCachedSearchLocal(t *testing.T) {
 	assert.Equal(t, 10, totalRows)
 }
 
+func TestCachedSearchPatchPages(t *testing.T) {
+	patch := &cachedQueryPatch{max: 4, operations: []interface{}{"+4", "-5", "+10", "-7"}}
+	fields, valid := patchCachedSearchPages(map[string]interface{}{"1": "3 1 5 9", "2": "3"}, patch)
+	assert.True(t, valid)
+	assert.Equal(t, map[string]interface{}{"1": "4 1 4 9 10", "2": "4"}, fields)
+
+	_, valid = patchCachedSearchPages(map[string]interface{}{"1": "5 1 5 9"}, patch)
+	assert.False(t, valid)
+	_, valid = patchCachedSearchPages(map[string]interface{}{"2": "3 1 5 9"}, patch)
+	assert.False(t, valid)
+	_, valid = patchCachedSearchPages(map[string]interface{}{"1": "4 1 2 3 5"}, patch)
+	assert.False(t, valid)
+}
+
 func BenchmarkCachedSearchLocal(b *testing.B) {
 	var entity testEntityIndexTestLocal
 	var entityRef testEntityIndexTestLocalRef
@@ -0,0 +1,85 @@
+package orm
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testEntityIndexTestRange struct {
+	ORM          `orm:"redisCache"`
+	ID           uint         `orm:"index=AgeIndex:2,NameAgeIndex:3"`
+	Name         string       `orm:"length=100;index=NameAgeIndex"`
+	Age          uint16       `orm:"index=AgeIndex,NameAgeIndex:2"`
+	Code         uint16       `orm:"unique=CodeIndex"`
+	IndexAgeIn   *CachedQuery `query:":Age IN ? ORDER BY :ID"`
+	IndexAgeFrom *CachedQuery `query:":Name = ? AND :Age >= ? ORDER BY :ID"`
+	IndexOneCode *CachedQuery `queryOne:":Code BETWEEN ? AND ?"`
+}
+
+func TestCachedSearchRange(t *testing.T) {
+	var entity *testEntityIndexTestRange
+	engine := PrepareTables(t, &Registry{}, entity)
+
+	for i := 1; i <= 10; i++ {
+		engine.Track(&testEntityIndexTestRange{Name: "Name", Age: uint16(i), Code: uint16(i)})
+	}
+	engine.Flush()
+
+	var rows []*testEntityIndexTestRange
+	totalRows := engine.CachedSearch(&rows, "IndexAgeIn", nil, []uint16{2, 4, 20})
+	assert.Equal(t, 2, totalRows)
+	totalRows = engine.CachedSearch(&rows, "IndexAgeFrom", nil, "Name", 8)
+	assert.Equal(t, 3, totalRows)
+	var row testEntityIndexTestRange
+	has := engine.CachedSearchOne(&row, "IndexOneCode", 5, 6)
+	assert.True(t, has)
+	assert.Equal(t, uint(5), row.ID)
+
+	engine.LoadByID(3, &row)
+	row.Age = 20
+	engine.TrackAndFlush(&row)
+
+	totalRows = engine.CachedSearch(&rows, "IndexAgeIn", nil, []uint16{2, 4, 20})
+	assert.Equal(t, 3, totalRows)
+	totalRows = engine.CachedSearch(&rows, "IndexAgeFrom", nil, "Name", 8)
+	assert.Equal(t, 4, totalRows)
+
+	engine.LoadByID(5, &row)
+	row.Code = 100
+	engine.TrackAndFlush(&row)
+	has = engine.CachedSearchOne(&row, "IndexOneCode", 5, 6)
+	assert.True(t, has)
+	assert.Equal(t, uint(6), row.ID)
+
+	for i := 1; i <= 2; i++ {
+		engine.Track(&testEntityIndexTestRange{Name: "Name " + strconv.Itoa(i), Age: 9, Code: uint16(10 + i)})
+	}
+	engine.Flush()
+	totalRows = engine.CachedSearch(&rows, "IndexAgeFrom", nil, "Name", 8)
+	assert.Equal(t, 4, totalRows)
+	totalRows = engine.CachedSearch(&rows, "IndexAgeFrom", nil, "Name 1", 8)
+	assert.Equal(t, 1, totalRows)
+}
+
+func TestCachedQueryBuckets(t *testing.T) {
+	ranges, fields, arguments := getCachedQueryBuckets(":Name = ? AND :Age >= ?")
+	assert.True(t, ranges)
+	assert.Equal(t, []string{"Name"}, fields)
+	assert.Equal(t, []int{0}, arguments)
+
+	ranges, fields, arguments = getCachedQueryBuckets(":Code BETWEEN ? AND ? AND :Name=?")
+	assert.True(t, ranges)
+	assert.Equal(t, []string{"Name"}, fields)
+	assert.Equal(t, []int{2}, arguments)
+
+	ranges, fields, _ = getCachedQueryBuckets(":Age IN ? AND :Age = ?")
+	assert.True(t, ranges)
+	assert.Len(t, fields, 0)
+
+	ranges, fields, arguments = getCachedQueryBuckets(":Name = ? AND :Age = ?")
+	assert.False(t, ranges)
+	assert.Equal(t, []string{"Name", "Age"}, fields)
+	assert.Equal(t, []int{0, 1}, arguments)
+}
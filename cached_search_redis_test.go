@@ -92,18 +92,18 @@ func TestCachedSearchRedis(t *testing.T) {
 	assert.Len(t, rows, 6)
 	assert.Equal(t, uint(1), rows[0].ID)
 	assert.Equal(t, uint(6), rows[1].ID)
-	assert.Len(t, DBLogger.Entries, 7)
+	assert.Len(t, DBLogger.Entries, 6)
 
 	totalRows = engine.CachedSearch(&rows, "IndexAge", pager, 10)
 	assert.Equal(t, 4, totalRows)
 	assert.Len(t, rows, 4)
 	assert.Equal(t, uint(2), rows[0].ID)
-	assert.Len(t, DBLogger.Entries, 8)
+	assert.Len(t, DBLogger.Entries, 6)
 
 	totalRows = engine.CachedSearch(&rows, "IndexAll", pager)
 	assert.Equal(t, 10, totalRows)
 	assert.Len(t, rows, 10)
-	assert.Len(t, DBLogger.Entries, 9)
+	assert.Len(t, DBLogger.Entries, 7)
 
 	engine.MarkToDelete(rows[1])
 	engine.Flush()
@@ -112,12 +112,12 @@ func TestCachedSearchRedis(t *testing.T) {
 	assert.Equal(t, 3, totalRows)
 	assert.Len(t, rows, 3)
 	assert.Equal(t, uint(3), rows[0].ID)
-	assert.Len(t, DBLogger.Entries, 11)
+	assert.Len(t, DBLogger.Entries, 8)
 
 	totalRows = engine.CachedSearch(&rows, "IndexAll", pager)
 	assert.Equal(t, 9, totalRows)
 	assert.Len(t, rows, 9)
-	assert.Len(t, DBLogger.Entries, 12)
+	assert.Len(t, DBLogger.Entries, 8)
 
 	entity = &testEntityIndexTestRedis{Name: "Name 11", Age: uint16(18)}
 	engine.Track(entity)
@@ -127,21 +127,21 @@ func TestCachedSearchRedis(t *testing.T) {
 	assert.Equal(t, 7, totalRows)
 	assert.Len(t, rows, 7)
 	assert.Equal(t, uint(11), rows[6].ID)
-	assert.Len(t, DBLogger.Entries, 15)
+	assert.Len(t, DBLogger.Entries, 10)
 
 	totalRows = engine.CachedSearch(&rows, "IndexAll", pager)
 	assert.Equal(t, 10, totalRows)
 	assert.Len(t, rows, 10)
-	assert.Len(t, DBLogger.Entries, 16)
+	assert.Len(t, DBLogger.Entries, 10)
 
 	totalRows = engine.CachedSearch(&rows, "IndexAll", pager)
 	assert.Equal(t, 10, totalRows)
 	assert.Len(t, rows, 10)
-	assert.Len(t, DBLogger.Entries, 16)
+	assert.Len(t, DBLogger.Entries, 10)
 
 	RedisLogger.Entries = make([]*log2.Entry, 0)
 	_ = engine.CachedSearch(&rows, "IndexAll", pager)
-	assert.Len(t, DBLogger.Entries, 16)
+	assert.Len(t, DBLogger.Entries, 10)
 	assert.Len(t, RedisLogger.Entries, 2)
 
 	entity = &testEntityIndexTestRedis{Name: "Name 12", Age: uint16(18)}
@@ -153,20 +153,20 @@ func TestCachedSearchRedis(t *testing.T) {
 	totalRows = engine.CachedSearch(&rows, "IndexAll", pager)
 	assert.Equal(t, 11, totalRows)
 	assert.Len(t, rows, 11)
-	assert.Len(t, DBLogger.Entries, 19)
+	assert.Len(t, DBLogger.Entries, 12)
 
 	RedisLogger.Entries = make([]*log2.Entry, 0)
 	var entityOne testEntityIndexTestRedis
 	has := engine.CachedSearchOne(&entityOne, "IndexName", "Name 10")
 	assert.True(t, has)
 	assert.Equal(t, uint(10), entityOne.ID)
-	assert.Len(t, DBLogger.Entries, 20)
+	assert.Len(t, DBLogger.Entries, 13)
 	assert.Len(t, RedisLogger.Entries, 3)
 
 	has = engine.CachedSearchOne(&entityOne, "IndexName", "Name 10")
 	assert.True(t, has)
 	assert.Equal(t, uint(10), entityOne.ID)
-	assert.Len(t, DBLogger.Entries, 20)
+	assert.Len(t, DBLogger.Entries, 13)
 	assert.Len(t, RedisLogger.Entries, 5)
 
 	engine.Track(&entityOne)
@@ -8,6 +8,7 @@ import (
 	"runtime/debug"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/segmentio/fasthash/fnv1a"
@@ -29,6 +30,8 @@ type dataDog struct {
 	ctx      []context.Context
 	hasError bool
 	counters map[string]uint
+	mutex    sync.Mutex
+	parent   *dataDog
 }
 
 type DataDog interface {
@@ -98,6 +101,8 @@ type httpAPM struct {
 
 func (s *apm) finish() {
 	dd := s.engine.dataDog
+	dd.mutex.Lock()
+	defer dd.mutex.Unlock()
 	for k, v := range dd.counters {
 		if v > 0 {
 			dd.span.SetTag("orm."+k, v)
@@ -220,6 +225,12 @@ func (dd *dataDog) SetAPMTag(key string, value interface{}) {
 }
 
 func (dd *dataDog) incrementCounter(key string, value uint) {
+	if dd.parent != nil {
+		dd.parent.incrementCounter(key, value)
+		return
+	}
+	dd.mutex.Lock()
+	defer dd.mutex.Unlock()
 	before, has := dd.counters[key]
 	if has {
 		dd.counters[key] = before + value
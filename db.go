@@ -13,16 +13,18 @@ const counterDBQuery = "db.query"
 const counterDBExec = "db.exec"
 
 type DBConfig struct {
-	dataSourceName string
-	code           string
-	databaseName   string
-	db             *sql.DB
+	dataSourceName   string
+	code             string
+	databaseName     string
+	db               *sql.DB
+	maxAllowedPacket int
 }
 
 type sqlClient interface {
 	Begin() error
 	Commit() error
 	Rollback() (bool, error)
+	InTransaction() bool
 	Exec(query string, args ...interface{}) (sql.Result, error)
 	QueryRow(query string, args ...interface{}) SQLRow
 	Query(query string, args ...interface{}) (SQLRows, error)
@@ -45,6 +47,10 @@ func (db *standardSQLClient) Begin() error {
 	return nil
 }
 
+func (db *standardSQLClient) InTransaction() bool {
+	return db.tx != nil
+}
+
 func (db *standardSQLClient) Commit() error {
 	if db.tx == nil {
 		return errors.Errorf("transaction not started")
@@ -119,10 +125,11 @@ type SQLRow interface {
 }
 
 type DB struct {
-	engine       *Engine
-	client       sqlClient
-	code         string
-	databaseName string
+	engine           *Engine
+	client           sqlClient
+	code             string
+	databaseName     string
+	maxAllowedPacket int
 }
 
 func (db *DB) GetDatabaseName() string {
@@ -158,10 +165,13 @@ func (db *DB) Commit() {
 		panic(err)
 	}
 	if db.engine.afterCommitLocalCacheSets != nil {
+		invalidated := make(map[string][]string)
 		for cacheCode, pairs := range db.engine.afterCommitLocalCacheSets {
 			cache := db.engine.GetLocalCache(cacheCode)
 			cache.MSet(pairs...)
+			addLocalCacheInvalidation(invalidated, cacheCode, pairs)
 		}
+		db.engine.publishLocalCacheInvalidation(invalidated)
 	}
 	db.engine.afterCommitLocalCacheSets = nil
 	if db.engine.afterCommitRedisCacheDeletes != nil {
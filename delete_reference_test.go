@@ -24,9 +24,22 @@ type testEntityDeleteReferenceRefCascade struct {
 	IndexReferenceOne *CachedQuery               `query:":ReferenceOne = ?"`
 }
 
+type testEntityDeleteReferenceRefSetNull struct {
+	ORM               `orm:"redisCache"`
+	ID                uint
+	ReferenceOne      *testEntityDeleteReference `orm:"onDelete=setNull;index=TestIndex"`
+	IndexReferenceOne *CachedQuery               `query:":ReferenceOne = ?"`
+}
+
+type testEntityDeleteReferenceInvalid struct {
+	ORM
+	ID           uint
+	ReferenceOne *testEntityDeleteReference `orm:"onDelete=setNull;required"`
+}
+
 func TestDeleteReference(t *testing.T) {
 	engine := PrepareTables(t, &Registry{}, testEntityDeleteReference{},
-		testEntityDeleteReferenceRefRestrict{}, testEntityDeleteReferenceRefCascade{})
+		testEntityDeleteReferenceRefRestrict{}, testEntityDeleteReferenceRefCascade{}, testEntityDeleteReferenceRefSetNull{})
 	entity1 := &testEntityDeleteReference{}
 	engine.Track(entity1)
 	engine.Flush()
@@ -61,4 +74,32 @@ func TestDeleteReference(t *testing.T) {
 
 	total = engine.CachedSearch(&rows, "IndexReferenceOne", nil, 2)
 	assert.Equal(t, 0, total)
+
+	entity3 := &testEntityDeleteReference{}
+	engine.TrackAndFlush(entity3)
+	entitySetNull := &testEntityDeleteReferenceRefSetNull{ReferenceOne: &testEntityDeleteReference{ID: entity3.ID}}
+	entitySetNull2 := &testEntityDeleteReferenceRefSetNull{ReferenceOne: &testEntityDeleteReference{ID: entity3.ID}}
+	engine.Track(entitySetNull, entitySetNull2)
+	engine.Flush()
+	var rowsSetNull []*testEntityDeleteReferenceRefSetNull
+	total = engine.CachedSearch(&rowsSetNull, "IndexReferenceOne", nil, entity3.ID)
+	assert.Equal(t, 2, total)
+	has := engine.LoadByID(uint64(entitySetNull.ID), entitySetNull)
+	assert.True(t, has)
+
+	engine.MarkToDelete(entity3)
+	engine.Flush()
+
+	total = engine.CachedSearch(&rowsSetNull, "IndexReferenceOne", nil, entity3.ID)
+	assert.Equal(t, 0, total)
+	entitySetNull = &testEntityDeleteReferenceRefSetNull{}
+	has = engine.LoadByID(uint64(entitySetNull2.ID), entitySetNull)
+	assert.True(t, has)
+	assert.Nil(t, entitySetNull.ReferenceOne)
+
+	registry := &Registry{}
+	registry.RegisterMySQLPool("root:root@tcp(localhost:3310)/test")
+	registry.RegisterEntity(&testEntityDeleteReference{}, &testEntityDeleteReferenceInvalid{})
+	_, err = registry.Validate()
+	assert.EqualError(t, err, "onDelete=setNull not allowed for required reference ReferenceOne in orm.testEntityDeleteReferenceInvalid")
 }
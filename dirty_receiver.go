@@ -8,6 +8,7 @@ type DirtyReceiver struct {
 	engine      *Engine
 	disableLoop bool
 	heartBeat   func()
+	workers     receiverWorkers
 }
 
 type DirtyQueueValue struct {
@@ -38,37 +39,38 @@ func (r *DirtyReceiver) SetHeartBeat(beat func()) {
 	r.heartBeat = beat
 }
 
+func (r *DirtyReceiver) SetWorkers(workers int) {
+	r.workers.count = workers
+}
+
+func (r *DirtyReceiver) Shutdown() {
+	r.workers.shutdown()
+}
+
 type DirtyHandler func(data []*DirtyData)
 
 func (r *DirtyReceiver) Digest(code string, handler DirtyHandler) {
-	channel := r.engine.GetRabbitMQQueue("dirty_queue_" + code)
-	consumer := channel.NewConsumer("default consumer")
-	defer consumer.Close()
-	if r.disableLoop {
-		consumer.DisableLoop()
-	}
-	if r.heartBeat != nil {
-		consumer.SetHeartBeat(r.heartBeat)
-	}
-	var value DirtyQueueValue
-	consumer.Consume(func(items [][]byte) {
-		data := make([]*DirtyData, len(items))
-		for i, item := range items {
-			_ = json.Unmarshal(item, &value)
-			t, has := r.engine.registry.entities[value.EntityName]
-			if !has {
-				return
-			}
-			tableSchema := getTableSchema(r.engine.registry, t)
-			v := &DirtyData{
-				TableSchema: tableSchema,
-				ID:          value.ID,
-				Added:       value.Added,
-				Updated:     value.Updated,
-				Deleted:     value.Deleted,
+	r.workers.run(r.engine, "dirty_queue_"+code, r.disableLoop, r.heartBeat, func(engine *Engine, consumer QueueConsumer) {
+		var value DirtyQueueValue
+		consumer.Consume(func(items [][]byte) {
+			data := make([]*DirtyData, len(items))
+			for i, item := range items {
+				_ = json.Unmarshal(item, &value)
+				t, has := engine.registry.entities[value.EntityName]
+				if !has {
+					return
+				}
+				tableSchema := getTableSchema(engine.registry, t)
+				v := &DirtyData{
+					TableSchema: tableSchema,
+					ID:          value.ID,
+					Added:       value.Added,
+					Updated:     value.Updated,
+					Deleted:     value.Deleted,
+				}
+				data[i] = v
 			}
-			data[i] = v
-		}
-		handler(data)
+			handler(data)
+		})
 	})
 }
@@ -1,6 +1,7 @@
 package orm
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 	"reflect"
@@ -14,6 +15,9 @@ import (
 	"github.com/apex/log/handlers/text"
 )
 
+const defaultTrackLimit = 10000
+const defaultFlushChunkRows = 1000
+
 type Engine struct {
 	registry                     *validatedRegistry
 	dbs                          map[string]*DB
@@ -29,6 +33,8 @@ type Engine struct {
 	logMetaData                  map[string]interface{}
 	trackedEntities              []Entity
 	trackedEntitiesCounter       int
+	trackLimit                   int
+	flushChunkRows               int
 	queryLoggers                 map[QueryLoggerSource]*logger
 	log                          *log
 	afterCommitLocalCacheSets    map[string][]interface{}
@@ -90,17 +96,51 @@ func (e *Engine) SetLogMetaData(key string, value interface{}) {
 	e.logMetaData[key] = value
 }
 
+func (e *Engine) newWorkerEngine() *Engine {
+	engine := e.registry.CreateEngine()
+	engine.dataDog.parent = e.dataDog
+	engine.dataDog.span = e.dataDog.span
+	engine.dataDog.ctx = append([]context.Context{}, e.dataDog.ctx...)
+	engine.trackLimit = e.trackLimit
+	engine.flushChunkRows = e.flushChunkRows
+	if e.queryLoggers != nil {
+		engine.queryLoggers = make(map[QueryLoggerSource]*logger, len(e.queryLoggers))
+		for source, l := range e.queryLoggers {
+			engine.queryLoggers[source] = l
+		}
+	}
+	for key, value := range e.logMetaData {
+		engine.SetLogMetaData(key, value)
+	}
+	return engine
+}
+
 func (e *Engine) Track(entity ...Entity) {
 	for _, entity := range entity {
 		initIfNeeded(e, entity)
 		e.trackedEntities = append(e.trackedEntities, entity)
 		e.trackedEntitiesCounter++
-		if e.trackedEntitiesCounter == 10000 {
-			panic(errors.Errorf("track limit 10000 exceeded"))
+		if e.trackLimit > 0 && e.trackedEntitiesCounter == e.trackLimit {
+			panic(errors.Errorf("track limit %d exceeded", e.trackLimit))
 		}
 	}
 }
 
+func (e *Engine) SetTrackLimit(limit int) {
+	e.trackLimit = limit
+}
+
+func (e *Engine) EnableChunkedFlush(maxRowsInQuery int) {
+	if maxRowsInQuery <= 0 {
+		maxRowsInQuery = defaultFlushChunkRows
+	}
+	e.flushChunkRows = maxRowsInQuery
+}
+
+func (e *Engine) DisableChunkedFlush() {
+	e.flushChunkRows = 0
+}
+
 func (e *Engine) TrackAndFlush(entity ...Entity) {
 	e.Track(entity...)
 	e.Flush()
@@ -173,6 +213,7 @@ func (e *Engine) FlushInTransactionWithLock(lockerPool string, lockName string,
 
 func (e *Engine) ClearTrackedEntities() {
 	e.trackedEntities = make([]Entity, 0)
+	e.trackedEntitiesCounter = 0
 }
 
 func (e *Engine) SetOnDuplicateKeyUpdate(update *Where, entity ...Entity) {
@@ -217,7 +258,7 @@ func (e *Engine) MarkDirty(entity Entity, queueCode string, ids ...uint64) {
 	if !has {
 		panic(errors.NotValidf("unknown dirty queue '%s'", queueCode))
 	}
-	channel := e.GetRabbitMQQueue("dirty_queue_" + queueCode)
+	channel := e.getQueue("dirty_queue_" + queueCode)
 	entityName := initIfNeeded(e, entity).tableSchema.t.String()
 	for _, id := range ids {
 		val := &DirtyQueueValue{Updated: true, ID: id, EntityName: entityName}
@@ -421,6 +462,14 @@ func (e *Engine) ClearByIDs(entity Entity, ids ...uint64) {
 	clearByIDs(e, entity, ids...)
 }
 
+func (e *Engine) WarmUpCache(entity Entity, where *Where, options *WarmUpCacheOptions) (total int) {
+	return warmUpCache(e, entity, where, options)
+}
+
+func (e *Engine) VerifyCache(entity Entity, where *Where, options *VerifyCacheOptions) []*CacheMismatch {
+	return verifyCache(e, entity, where, options)
+}
+
 func (e *Engine) FlushInCache(entities ...Entity) {
 	flushInCache(e, entities...)
 }
@@ -457,12 +506,24 @@ func (e *Engine) flushTrackedEntities(lazy bool, transaction bool) {
 		return
 	}
 	var dbPools map[string]*DB
-	if transaction {
+	if transaction || (!lazy && e.flushChunkRows > 0 && e.trackedEntitiesCounter > e.flushChunkRows) {
 		dbPools = make(map[string]*DB)
 		for _, entity := range e.trackedEntities {
 			db := entity.getORM().tableSchema.GetMysql(e)
 			dbPools[db.code] = db
 		}
+	}
+	if !transaction && dbPools != nil {
+		//chunks are flushed in one transaction unless caller already started own transaction
+		for _, db := range dbPools {
+			if db.client.InTransaction() {
+				dbPools = nil
+				break
+			}
+		}
+		transaction = dbPools != nil
+	}
+	if transaction {
 		for _, db := range dbPools {
 			db.Begin()
 		}
@@ -28,11 +28,13 @@ func TestEngine(t *testing.T) {
 	engine := validatedRegistry.CreateEngine()
 	assert.NotNil(t, engine)
 
+	engine.EnableChunkedFlush(0)
 	require.PanicsWithError(t, "track limit 10000 exceeded", func() {
 		for i := 0; i < 10001; i++ {
 			engine.Track(&testEntityEngine{})
 		}
 	})
+	engine.DisableChunkedFlush()
 
 	engine.ClearTrackedEntities()
 	assert.Len(t, engine.trackedEntities, 0)
@@ -47,6 +47,12 @@ func flush(engine *Engine, lazy bool, transaction bool, entities ...Entity) {
 	dirtyQueues := make(map[string][]*DirtyQueueValue)
 	logQueues := make([]*LogQueueValue, 0)
 	lazyMap := make(map[string]interface{})
+	var localCachePatches, redisCachePatches map[string]map[string]*cachedQueryPatch
+	if !lazy && !transaction {
+		localCachePatches = make(map[string]map[string]*cachedQueryPatch)
+		redisCachePatches = make(map[string]map[string]*cachedQueryPatch)
+	}
+	patchable := localCachePatches != nil
 
 	var referencesToFlash map[Entity]Entity
 
@@ -126,7 +132,7 @@ func flush(engine *Engine, lazy bool, transaction bool, entities ...Entity) {
 						injectBind(entity, bind)
 						entity.getORM().attributes.idElem.SetUint(uint64(lastID))
 						logQueues = updateCacheForInserted(entity, lazy, uint64(lastID), bind, localCacheSets,
-							localCacheDeletes, redisKeysToDelete, dirtyQueues, logQueues)
+							localCacheDeletes, redisKeysToDelete, nil, nil, dirtyQueues, logQueues)
 						if affected == 2 {
 							_ = loadByID(engine, uint64(lastID), entity, false)
 						}
@@ -226,18 +232,20 @@ func flush(engine *Engine, lazy bool, transaction bool, entities ...Entity) {
 			localCache, hasLocalCache := schema.GetLocalCache(engine)
 			redisCache, hasRedis := schema.GetRedisCache(engine)
 			if hasLocalCache {
-				addLocalCacheSet(localCacheSets, db.GetPoolCode(), localCache.code, schema.getCacheKey(currentID), buildLocalCacheValue(entity))
-				keys := getCacheQueriesKeys(schema, bind, dbData, false)
+				addLocalCacheSet(localCacheSets, db.GetPoolCode(), localCache.code, schema.getCacheKey(currentID), schema.localCacheValue(buildLocalCacheValue(entity)))
+				keys := getCacheQueriesKeys(schema, bind, dbData, false, patchable)
 				addCacheDeletes(localCacheDeletes, localCache.code, keys...)
-				keys = getCacheQueriesKeys(schema, bind, old, false)
+				keys = getCacheQueriesKeys(schema, bind, old, false, patchable)
 				addCacheDeletes(localCacheDeletes, localCache.code, keys...)
+				addCacheQueriesPatches(localCachePatches, localCache.code, schema, bind, dbData, old, currentID)
 			}
 			if hasRedis {
 				addCacheDeletes(redisKeysToDelete, redisCache.code, schema.getCacheKey(currentID))
-				keys := getCacheQueriesKeys(schema, bind, dbData, false)
+				keys := getCacheQueriesKeys(schema, bind, dbData, false, patchable)
 				addCacheDeletes(redisKeysToDelete, redisCache.code, keys...)
-				keys = getCacheQueriesKeys(schema, bind, old, false)
+				keys = getCacheQueriesKeys(schema, bind, old, false, patchable)
 				addCacheDeletes(redisKeysToDelete, redisCache.code, keys...)
+				addCacheQueriesPatches(redisCachePatches, redisCache.code, schema, bind, dbData, old, currentID)
 			}
 			addDirtyQueues(dirtyQueues, bind, schema, currentID, "u")
 			logQueues = addToLogQueue(logQueues, schema, currentID, old, bind, entity.getORM().attributes.logMeta)
@@ -271,41 +279,56 @@ func flush(engine *Engine, lazy bool, transaction bool, entities ...Entity) {
 			finalValues[key] = fmt.Sprintf("`%s`", val)
 		}
 		/* #nosec */
-		sql := fmt.Sprintf("INSERT INTO %s(%s) VALUES %s", schema.tableName, strings.Join(finalValues, ","), insertValues[typeOf])
-		for i := 1; i < totalInsert[typeOf]; i++ {
-			sql += "," + insertValues[typeOf]
-		}
-		id := uint64(0)
+		sqlPrefix := fmt.Sprintf("INSERT INTO %s(%s) VALUES ", schema.tableName, strings.Join(finalValues, ","))
 		db := schema.GetMysql(engine)
-		if lazy {
-			fillLazyQuery(lazyMap, db.GetPoolCode(), sql, insertArguments[typeOf])
-		} else {
-			res := db.Exec(sql, insertArguments[typeOf]...)
-			insertID, err := res.LastInsertId()
-			if err != nil {
-				panic(err)
-			}
-			id = uint64(insertID)
-		}
-		for key, entity := range insertReflectValues[typeOf] {
-			bind := insertBinds[typeOf][key]
-			injectBind(entity, bind)
-			insertedID := entity.GetID()
-			if insertedID == 0 {
-				entity.getORM().attributes.idElem.SetUint(id)
-				insertedID = id
-				id++
-			}
+		columns := len(values)
+		arguments := insertArguments[typeOf]
+		chunks := getFlushChunks(engine, db, len(sqlPrefix), totalInsert[typeOf], func(row int) int {
+			return len(insertValues[typeOf]) + 1 + estimateArgumentsSize(arguments[row*columns:(row+1)*columns])
+		})
+		for _, chunk := range chunks {
+			var builder strings.Builder
+			builder.Grow(len(sqlPrefix) + (len(insertValues[typeOf])+1)*(chunk[1]-chunk[0]))
+			builder.WriteString(sqlPrefix)
+			builder.WriteString(insertValues[typeOf])
+			for i := chunk[0] + 1; i < chunk[1]; i++ {
+				builder.WriteString(",")
+				builder.WriteString(insertValues[typeOf])
+			}
+			sql := builder.String()
+			chunkArguments := arguments[chunk[0]*columns : chunk[1]*columns]
+			id := uint64(0)
+			if lazy {
+				fillLazyQuery(lazyMap, db.GetPoolCode(), sql, chunkArguments)
+			} else {
+				res := db.Exec(sql, chunkArguments...)
+				insertID, err := res.LastInsertId()
+				if err != nil {
+					panic(err)
+				}
+				id = uint64(insertID)
+			}
+			for key := chunk[0]; key < chunk[1]; key++ {
+				entity := insertReflectValues[typeOf][key]
+				bind := insertBinds[typeOf][key]
+				injectBind(entity, bind)
+				insertedID := entity.GetID()
+				if insertedID == 0 {
+					entity.getORM().attributes.idElem.SetUint(id)
+					insertedID = id
+					id++
+				}
 
-			logQueues = updateCacheForInserted(entity, lazy, insertedID, bind, localCacheSets, localCacheDeletes,
-				redisKeysToDelete, dirtyQueues, logQueues)
-			localCache, hasLocalCache := schema.GetLocalCache(engine)
-			if hasLocalCache {
-				addLocalCacheSet(localCacheSets, db.GetPoolCode(), localCache.code, schema.getCacheKey(insertedID), buildLocalCacheValue(entity))
-			}
-			afterSaveInterface, is := entity.(AfterSavedInterface)
-			if is {
-				afterSaveInterface.AfterSaved(engine)
+				logQueues = updateCacheForInserted(entity, lazy, insertedID, bind, localCacheSets, localCacheDeletes,
+					redisKeysToDelete, localCachePatches, redisCachePatches, dirtyQueues, logQueues)
+				localCache, hasLocalCache := schema.GetLocalCache(engine)
+				if hasLocalCache {
+					addLocalCacheSet(localCacheSets, db.GetPoolCode(), localCache.code, schema.getCacheKey(insertedID), schema.localCacheValue(buildLocalCacheValue(entity)))
+				}
+				afterSaveInterface, is := entity.(AfterSavedInterface)
+				if is {
+					afterSaveInterface.AfterSaved(engine)
+				}
 			}
 		}
 	}
@@ -317,59 +340,48 @@ func flush(engine *Engine, lazy bool, transaction bool, entities ...Entity) {
 			ids[i] = id
 			i++
 		}
-		/* #nosec */
-		sql := fmt.Sprintf("DELETE FROM `%s` WHERE %s", schema.tableName, NewWhere("`ID` IN ?", ids))
 		db := schema.GetMysql(engine)
-		if lazy {
-			fillLazyQuery(lazyMap, db.GetPoolCode(), sql, ids)
-		} else {
-			usage := schema.GetUsage(engine.registry)
-			if len(usage) > 0 {
-				for refT, refColumns := range usage {
-					for _, refColumn := range refColumns {
-						refSchema := getTableSchema(engine.registry, refT)
-						_, isCascade := refSchema.tags[refColumn]["cascade"]
-						if isCascade {
-							subValue := reflect.New(reflect.SliceOf(reflect.PtrTo(refT)))
-							subElem := subValue.Elem()
-							sub := subValue.Interface()
-							pager := &Pager{CurrentPage: 1, PageSize: 1000}
-							where := NewWhere(fmt.Sprintf("`%s` IN ?", refColumn), ids)
-							for {
-								engine.Search(where, pager, sub)
-								total := subElem.Len()
-								if total == 0 {
-									break
-								}
-								toDeleteAll := make([]Entity, total)
-								for i := 0; i < total; i++ {
-									toDeleteValue := subElem.Index(i).Interface().(Entity)
-									engine.MarkToDelete(toDeleteValue)
-									toDeleteAll[i] = toDeleteValue
-								}
-								flush(engine, transaction, lazy, toDeleteAll...)
-							}
-						}
+		/* #nosec */
+		sqlPrefix := fmt.Sprintf("DELETE FROM `%s` WHERE `ID` IN ", schema.tableName)
+		chunks := getFlushChunks(engine, db, len(sqlPrefix), len(ids), func(row int) int {
+			return estimateArgumentsSize(ids[row:row+1]) + 2
+		})
+		for _, chunk := range chunks {
+			chunkIDs := ids[chunk[0]:chunk[1]]
+			/* #nosec */
+			sql := fmt.Sprintf("DELETE FROM `%s` WHERE %s", schema.tableName, NewWhere("`ID` IN ?", chunkIDs))
+			for refT, refColumns := range schema.GetUsage(engine.registry) {
+				refSchema := getTableSchema(engine.registry, refT)
+				for _, refColumn := range refColumns {
+					onDelete := refSchema.getOnDelete(refColumn)
+					if onDelete == "SET NULL" || (onDelete == "CASCADE" && !lazy) {
+						flushReferencesOnDelete(engine, lazy, transaction, refSchema, refColumn, onDelete == "SET NULL", chunkIDs)
 					}
 				}
 			}
-			_ = db.Exec(sql, ids...)
+			if lazy {
+				fillLazyQuery(lazyMap, db.GetPoolCode(), sql, chunkIDs)
+				continue
+			}
+			_ = db.Exec(sql, chunkIDs...)
 		}
 
 		localCache, hasLocalCache := schema.GetLocalCache(engine)
 		redisCache, hasRedis := schema.GetRedisCache(engine)
 		if hasLocalCache {
 			for id, bind := range deleteBinds {
-				addLocalCacheSet(localCacheSets, db.GetPoolCode(), localCache.code, schema.getCacheKey(id), "nil")
-				keys := getCacheQueriesKeys(schema, bind, bind, true)
+				addLocalCacheSet(localCacheSets, db.GetPoolCode(), localCache.code, schema.getCacheKey(id), schema.localCacheValue("nil"))
+				keys := getCacheQueriesKeys(schema, bind, bind, true, patchable)
 				addCacheDeletes(localCacheDeletes, localCache.code, keys...)
+				addCacheQueriesPatches(localCachePatches, localCache.code, schema, bind, nil, bind, id)
 			}
 		}
 		if hasRedis {
 			for id, bind := range deleteBinds {
 				addCacheDeletes(redisKeysToDelete, redisCache.code, schema.getCacheKey(id))
-				keys := getCacheQueriesKeys(schema, bind, bind, true)
+				keys := getCacheQueriesKeys(schema, bind, bind, true, patchable)
 				addCacheDeletes(redisKeysToDelete, redisCache.code, keys...)
+				addCacheQueriesPatches(redisCachePatches, redisCache.code, schema, bind, nil, bind, id)
 			}
 		}
 		for id, bind := range deleteBinds {
@@ -377,11 +389,13 @@ func flush(engine *Engine, lazy bool, transaction bool, entities ...Entity) {
 			logQueues = addToLogQueue(logQueues, schema, id, bind, nil, nil)
 		}
 	}
+	localCacheInvalidated := make(map[string][]string)
 	for _, values := range localCacheSets {
 		for cacheCode, keys := range values {
 			cache := engine.GetLocalCache(cacheCode)
 			if !transaction {
 				cache.MSet(keys...)
+				addLocalCacheInvalidation(localCacheInvalidated, cacheCode, keys)
 			} else {
 				if engine.afterCommitLocalCacheSets == nil {
 					engine.afterCommitLocalCacheSets = make(map[string][]interface{})
@@ -390,6 +404,12 @@ func flush(engine *Engine, lazy bool, transaction bool, entities ...Entity) {
 			}
 		}
 	}
+	for cacheCode, patches := range localCachePatches {
+		applyCacheQueriesPatchesLocal(engine.GetLocalCache(cacheCode), patches)
+		for key := range patches {
+			localCacheInvalidated[cacheCode] = append(localCacheInvalidated[cacheCode], key)
+		}
+	}
 	for cacheCode, allKeys := range localCacheDeletes {
 		cache := engine.GetLocalCache(cacheCode)
 		keys := make([]string, len(allKeys))
@@ -407,10 +427,12 @@ func flush(engine *Engine, lazy bool, transaction bool, entities ...Entity) {
 			deletesLocalCache.(map[string][]string)[cacheCode] = keys
 		} else {
 			cache.Remove(keys...)
+			localCacheInvalidated[cacheCode] = append(localCacheInvalidated[cacheCode], keys...)
 		}
 	}
+	engine.publishLocalCacheInvalidation(localCacheInvalidated)
+	redisKeysToDeleteNow := make(map[string][]string)
 	for cacheCode, allKeys := range redisKeysToDelete {
-		cache := engine.GetRedis(cacheCode)
 		keys := make([]string, len(allKeys))
 		i := 0
 		for key := range allKeys {
@@ -426,7 +448,7 @@ func flush(engine *Engine, lazy bool, transaction bool, entities ...Entity) {
 			deletesRedisCache.(map[string][]string)[cacheCode] = keys
 		} else {
 			if !transaction {
-				cache.Del(keys...)
+				redisKeysToDeleteNow[cacheCode] = keys
 			} else {
 				if engine.afterCommitRedisCacheDeletes == nil {
 					engine.afterCommitRedisCacheDeletes = make(map[string][]string)
@@ -435,18 +457,29 @@ func flush(engine *Engine, lazy bool, transaction bool, entities ...Entity) {
 			}
 		}
 	}
+	for cacheCode, patches := range redisCachePatches {
+		invalidateRedisCache(engine.GetRedis(cacheCode), patches, redisKeysToDeleteNow[cacheCode])
+		delete(redisKeysToDeleteNow, cacheCode)
+	}
+	for cacheCode, keys := range redisKeysToDeleteNow {
+		engine.GetRedis(cacheCode).Del(keys...)
+	}
 	if len(lazyMap) > 0 {
-		channel := engine.GetRabbitMQQueue(lazyQueueName)
+		channel := engine.getQueue(lazyQueueName)
 		channel.Publish(serializeForLazyQueue(lazyMap))
 	}
 	for k, v := range dirtyQueues {
-		channel := engine.GetRabbitMQQueue("dirty_queue_" + k)
-		for _, k := range v {
-			asJSON, _ := jsoniter.ConfigFastest.Marshal(k)
-			channel.Publish(asJSON)
+		bodies := make([][]byte, len(v))
+		for i, k := range v {
+			bodies[i], _ = jsoniter.ConfigFastest.Marshal(k)
 		}
+		publishBatch(engine.getQueue("dirty_queue_"+k), bodies)
+	}
+	if len(logQueues) == 0 {
+		return
 	}
-	for _, val := range logQueues {
+	logBodies := make([][]byte, len(logQueues))
+	for i, val := range logQueues {
 		if val.Meta == nil {
 			val.Meta = engine.logMetaData
 		} else {
@@ -454,10 +487,80 @@ func flush(engine *Engine, lazy bool, transaction bool, entities ...Entity) {
 				val.Meta[k] = v
 			}
 		}
-		asJSON, _ := jsoniter.ConfigFastest.Marshal(val)
-		channel := engine.GetRabbitMQQueue(logQueueName)
-		channel.Publish(asJSON)
+		logBodies[i], _ = jsoniter.ConfigFastest.Marshal(val)
+	}
+	publishBatch(engine.getQueue(logQueueName), logBodies)
+}
+
+func flushReferencesOnDelete(engine *Engine, lazy bool, transaction bool, refSchema *tableSchema, refColumn string, setNull bool, ids []interface{}) {
+	subValue := reflect.New(reflect.SliceOf(reflect.PtrTo(refSchema.t)))
+	subElem := subValue.Elem()
+	sub := subValue.Interface()
+	pager := &Pager{CurrentPage: 1, PageSize: 1000}
+	where := NewWhere(fmt.Sprintf("`%s` IN ?", refColumn), ids)
+	for {
+		engine.Search(where, pager, sub)
+		total := subElem.Len()
+		if total == 0 {
+			break
+		}
+		toFlush := make([]Entity, total)
+		for i := 0; i < total; i++ {
+			child := subElem.Index(i).Interface().(Entity)
+			if setNull {
+				field := child.getORM().attributes.elem.FieldByName(refColumn)
+				field.Set(reflect.Zero(field.Type()))
+			} else {
+				engine.MarkToDelete(child)
+			}
+			toFlush[i] = child
+		}
+		flush(engine, lazy, transaction, toFlush...)
+		if lazy {
+			pager.CurrentPage++
+		}
+	}
+}
+
+func getFlushChunks(engine *Engine, db *DB, baseSize int, rows int, rowSize func(row int) int) [][2]int {
+	if engine.flushChunkRows <= 0 && db.maxAllowedPacket <= 0 {
+		return [][2]int{{0, rows}}
+	}
+	chunks := make([][2]int, 0)
+	start := 0
+	size := baseSize
+	for row := 0; row < rows; row++ {
+		current := rowSize(row)
+		full := engine.flushChunkRows > 0 && row-start == engine.flushChunkRows
+		if !full && db.maxAllowedPacket > 0 && row > start {
+			full = size+current > db.maxAllowedPacket
+		}
+		if full {
+			chunks = append(chunks, [2]int{start, row})
+			start = row
+			size = baseSize
+		}
+		size += current
+	}
+	if rows > start {
+		chunks = append(chunks, [2]int{start, rows})
+	}
+	return chunks
+}
+
+func estimateArgumentsSize(arguments []interface{}) int {
+	size := 0
+	for _, argument := range arguments {
+		switch v := argument.(type) {
+		case nil:
+			size += 4
+		case string:
+			size += len(v) + 4
+		default:
+			size += len(fmt.Sprintf("%v", v)) + 4
+		}
 	}
+	return size
 }
 
 func serializeForLazyQueue(lazyMap map[string]interface{}) []byte {
@@ -683,10 +786,14 @@ func createBind(id uint64, tableSchema *tableSchema, t reflect.Type, value refle
 	return
 }
 
-func getCacheQueriesKeys(schema *tableSchema, bind map[string]interface{}, data map[string]interface{}, addedDeleted bool) (keys []string) {
+func getCacheQueriesKeys(schema *tableSchema, bind map[string]interface{}, data map[string]interface{}, addedDeleted bool,
+	skipPatchable bool) (keys []string) {
 	keys = make([]string, 0)
 
 	for indexName, definition := range schema.cachedIndexesAll {
+		if skipPatchable && definition.Patchable {
+			continue
+		}
 		if !addedDeleted && schema.hasFakeDelete {
 			_, addedDeleted = bind["FakeDelete"]
 		}
@@ -697,7 +804,11 @@ func getCacheQueriesKeys(schema *tableSchema, bind map[string]interface{}, data
 			_, has := bind[trackedField]
 			if has {
 				attributes := make([]interface{}, 0)
-				for _, trackedFieldSub := range definition.QueryFields {
+				queryFields := definition.QueryFields
+				if definition.Ranges {
+					queryFields = definition.BucketFields
+				}
+				for _, trackedFieldSub := range queryFields {
 					val := data[trackedFieldSub]
 					if !schema.hasFakeDelete || trackedFieldSub != "FakeDelete" {
 						attributes = append(attributes, val)
@@ -806,7 +917,8 @@ func convertToError(err error) error {
 
 func updateCacheForInserted(entity Entity, lazy bool, id uint64,
 	bind map[string]interface{}, localCacheSets map[string]map[string][]interface{}, localCacheDeletes map[string]map[string]bool,
-	redisKeysToDelete map[string]map[string]bool, dirtyQueues map[string][]*DirtyQueueValue,
+	redisKeysToDelete map[string]map[string]bool, localCachePatches map[string]map[string]*cachedQueryPatch,
+	redisCachePatches map[string]map[string]*cachedQueryPatch, dirtyQueues map[string][]*DirtyQueueValue,
 	logQueues []*LogQueueValue) []*LogQueueValue {
 	schema := entity.getORM().tableSchema
 	engine := entity.getORM().engine
@@ -814,19 +926,40 @@ func updateCacheForInserted(entity Entity, lazy bool, id uint64,
 	redisCache, hasRedis := schema.GetRedisCache(engine)
 	if hasLocalCache {
 		if !lazy {
-			addLocalCacheSet(localCacheSets, schema.GetMysql(engine).GetPoolCode(), localCache.code, schema.getCacheKey(id), buildLocalCacheValue(entity))
+			addLocalCacheSet(localCacheSets, schema.GetMysql(engine).GetPoolCode(), localCache.code, schema.getCacheKey(id), schema.localCacheValue(buildLocalCacheValue(entity)))
 		} else {
 			addCacheDeletes(localCacheDeletes, localCache.code, schema.getCacheKey(id))
 		}
-		keys := getCacheQueriesKeys(schema, bind, bind, true)
+		keys := getCacheQueriesKeys(schema, bind, bind, true, localCachePatches != nil)
 		addCacheDeletes(localCacheDeletes, localCache.code, keys...)
+		addCacheQueriesPatches(localCachePatches, localCache.code, schema, bind, bind, nil, id)
 	}
 	if hasRedis {
 		addCacheDeletes(redisKeysToDelete, redisCache.code, schema.getCacheKey(id))
-		keys := getCacheQueriesKeys(schema, bind, bind, true)
+		keys := getCacheQueriesKeys(schema, bind, bind, true, redisCachePatches != nil)
 		addCacheDeletes(redisKeysToDelete, redisCache.code, keys...)
+		addCacheQueriesPatches(redisCachePatches, redisCache.code, schema, bind, bind, nil, id)
 	}
 	addDirtyQueues(dirtyQueues, bind, schema, id, "i")
 	logQueues = addToLogQueue(logQueues, schema, id, nil, bind, entity.getORM().attributes.logMeta)
 	return logQueues
 }
+
+func invalidateRedisCache(cache *RedisCache, patches map[string]*cachedQueryPatch, keys []string) {
+	if len(patches) == 0 || (len(patches) == 1 && len(keys) == 0) {
+		applyCacheQueriesPatchesRedis(cache, patches)
+		if len(keys) > 0 {
+			cache.Del(keys...)
+		}
+		return
+	}
+	cache.Pipeline(func(pipeline *RedisPipeline) {
+		for key, patch := range patches {
+			args := append([]interface{}{patch.max, idsOnCachePage}, patch.operations...)
+			pipeline.Eval(cachedSearchPatchScript, []string{key}, args...)
+		}
+		if len(keys) > 0 {
+			pipeline.Del(keys...)
+		}
+	})
+}
@@ -7,7 +7,7 @@ import (
 func flushInCache(engine *Engine, entities ...Entity) {
 	invalidEntities := make([]Entity, 0)
 	validEntities := make([][]byte, 0)
-	redisValues := make(map[string][]interface{})
+	redisValues := make(map[string]map[int][]interface{})
 
 	for _, entity := range entities {
 		orm := initIfNeeded(engine, entity)
@@ -29,11 +29,11 @@ func flushInCache(engine *Engine, entities ...Entity) {
 			}
 			injectBind(entity, bind)
 			entityCacheKey := schema.getCacheKey(id)
-			entityCacheValue := buildRedisValue(entity.(Entity))
+			entityCacheValue := buildRedisValue(engine, entity.(Entity))
 			if redisValues[cache.code] == nil {
-				redisValues[cache.code] = make([]interface{}, 0)
+				redisValues[cache.code] = make(map[int][]interface{})
 			}
-			redisValues[cache.code] = append(redisValues[cache.code], entityCacheKey, entityCacheValue)
+			redisValues[cache.code][schema.redisTTL] = append(redisValues[cache.code][schema.redisTTL], entityCacheKey, entityCacheValue)
 
 			validEntities = append(validEntities, createDirtyQueueMember(entityName, id))
 		}
@@ -42,12 +42,15 @@ func flushInCache(engine *Engine, entities ...Entity) {
 		flush(engine, false, false, invalidEntities...)
 	}
 	if len(validEntities) > 0 {
-		channel := engine.GetRabbitMQQueue(flushCacheQueueName)
-		for _, v := range validEntities {
-			channel.Publish(v)
-		}
-		for cacheCode, keys := range redisValues {
-			engine.GetRedis(cacheCode).MSet(keys...)
+		publishBatch(engine.getQueue(flushCacheQueueName), validEntities)
+		for cacheCode, values := range redisValues {
+			for ttl, keys := range values {
+				if ttl > 0 {
+					engine.GetRedis(cacheCode).MSetEx(ttl, keys...)
+				} else {
+					engine.GetRedis(cacheCode).MSet(keys...)
+				}
+			}
 		}
 	}
 }
@@ -184,6 +184,73 @@ func TestFlushInTransaction(t *testing.T) {
 	assert.Equal(t, "[ORM][LOCKER][RELEASE]", logger.Entries[5].Message)
 }
 
+func TestFlushChunked(t *testing.T) {
+	var entity testEntityFlushTransactionLocal
+	engine := PrepareTables(t, &Registry{}, entity)
+	logger := memory.New()
+	engine.AddQueryLogger(logger, log2.InfoLevel, QueryLoggerSourceDB)
+
+	engine.SetTrackLimit(3)
+	assert.PanicsWithError(t, "track limit 3 exceeded", func() {
+		for i := 0; i < 3; i++ {
+			engine.Track(&testEntityFlushTransactionLocal{})
+		}
+	})
+	engine.ClearTrackedEntities()
+	engine.SetTrackLimit(0)
+
+	engine.EnableChunkedFlush(4)
+	entities := make([]*testEntityFlushTransactionLocal, 10)
+	for i := 0; i < 10; i++ {
+		entities[i] = &testEntityFlushTransactionLocal{Name: "Name " + strconv.Itoa(i)}
+		engine.Track(entities[i])
+	}
+	engine.Flush()
+	assert.Len(t, logger.Entries, 5)
+	assert.Equal(t, "[ORM][MYSQL][BEGIN]", logger.Entries[0].Message)
+	assert.Equal(t, "[ORM][MYSQL][COMMIT]", logger.Entries[4].Message)
+	for i, e := range entities {
+		assert.Equal(t, uint16(i+1), e.ID)
+	}
+
+	logger.Entries = make([]*log2.Entry, 0)
+	for _, e := range entities {
+		engine.MarkToDelete(e)
+	}
+	engine.Flush()
+	assert.Len(t, logger.Entries, 5)
+
+	for i := 0; i < 11; i++ {
+		engine.Track(&testEntityFlushTransactionLocal{ID: uint16(200 + i), Name: "Name " + strconv.Itoa(i)})
+	}
+	engine.Track(&testEntityFlushTransactionLocal{ID: 200, Name: "Duplicated"})
+	assert.Panics(t, func() {
+		engine.Flush()
+	})
+	engine.ClearTrackedEntities()
+	ids := engine.SearchIDs(NewWhere("1"), &Pager{CurrentPage: 1, PageSize: 100}, &entity)
+	assert.Len(t, ids, 0)
+
+	engine.GetMysql().Begin()
+	for i := 0; i < 5; i++ {
+		engine.Track(&testEntityFlushTransactionLocal{Name: "Name " + strconv.Itoa(i)})
+	}
+	assert.NotPanics(t, func() {
+		engine.Flush()
+	})
+	engine.GetMysql().Commit()
+	ids = engine.SearchIDs(NewWhere("1"), &Pager{CurrentPage: 1, PageSize: 100}, &entity)
+	assert.Len(t, ids, 5)
+
+	engine.DisableChunkedFlush()
+	for i := 0; i < 10; i++ {
+		engine.Track(&testEntityFlushTransactionLocal{Name: "Name " + strconv.Itoa(i)})
+	}
+	logger.Entries = make([]*log2.Entry, 0)
+	engine.Flush()
+	assert.Len(t, logger.Entries, 1)
+}
+
 func TestFlushErrors(t *testing.T) {
 	entity := &testEntityErrors{Name: "Name"}
 	engine := PrepareTables(t, &Registry{}, entity)
@@ -1,8 +1,8 @@
 package orm
 
 import (
-	"encoding/json"
 	"fmt"
+	"reflect"
 )
 
 func loadByID(engine *Engine, id uint64, entity Entity, useCache bool, references ...string) (found bool) {
@@ -33,40 +33,74 @@ func loadByID(engine *Engine, id uint64, entity Entity, useCache bool, reference
 			if row == "nil" {
 				return false
 			}
-			var decoded []string
-			_ = json.Unmarshal([]byte(row), &decoded)
-			fillFromDBRow(id, engine, decoded, entity)
+			fillFromDBRow(id, engine, decodeRedisValue(row), entity)
 			if len(references) > 0 {
 				warmUpReferences(engine, schema, orm.attributes.elem, references, false)
 			}
 			return true
 		}
 	}
-	found = searchRow(false, engine, NewWhere("`ID` = ?", id), entity, nil)
+	if !useCache || (!hasLocalCache && !hasRedis) {
+		found = searchRow(false, engine, NewWhere("`ID` = ?", id), entity, nil)
+		if found && len(references) > 0 {
+			warmUpReferences(engine, schema, orm.attributes.elem, references, false)
+		}
+		return found
+	}
+	row, _ := engine.registry.loadByIDGroup.do(cacheKey, func() interface{} {
+		return loadByIDFromDB(engine, id, schema, cacheKey, localCache, redisCache)
+	})
+	if row.([]string) == nil {
+		return false
+	}
+	fillFromDBRow(id, engine, row.([]string), entity)
+	if len(references) > 0 {
+		warmUpReferences(engine, schema, orm.attributes.elem, references, false)
+	}
+	return true
+}
+
+func loadByIDFromDB(engine *Engine, id uint64, schema *tableSchema, cacheKey string, localCache *LocalCache, redisCache *RedisCache) []string {
+	if redisCache != nil {
+		row, has, unlock := redisCache.stampedeLock(cacheKey)
+		if has {
+			if row == "nil" {
+				if localCache != nil {
+					localCache.Set(cacheKey, schema.localCacheValue("nil"))
+				}
+				return nil
+			}
+			value := decodeRedisValue(row)
+			if localCache != nil {
+				localCache.Set(cacheKey, schema.localCacheValue(value))
+			}
+			return value
+		}
+		defer unlock()
+	}
+	entity := reflect.New(schema.t).Interface().(Entity)
+	found := searchRow(false, engine, NewWhere("`ID` = ?", id), entity, nil)
 	if !found {
 		if localCache != nil {
-			localCache.Set(cacheKey, "nil")
+			localCache.Set(cacheKey, schema.localCacheValue("nil"))
 		}
 		if redisCache != nil {
-			redisCache.Set(cacheKey, "nil", 60)
+			redisCache.Set(cacheKey, "nil", schema.redisNilTTL())
 		}
-		return false
+		return nil
 	}
-	if localCache != nil && useCache {
-		localCache.Set(cacheKey, buildLocalCacheValue(entity))
+	value := buildLocalCacheValue(entity)
+	if localCache != nil {
+		localCache.Set(cacheKey, schema.localCacheValue(value))
 	}
-	if redisCache != nil && useCache {
-		redisCache.Set(cacheKey, buildRedisValue(entity), 0)
+	if redisCache != nil {
+		redisCache.Set(cacheKey, buildRedisValue(engine, entity), schema.redisTTL)
 	}
-	if len(references) > 0 {
-		warmUpReferences(engine, schema, orm.attributes.elem, references, false)
-	}
-	return true
+	return value
 }
 
-func buildRedisValue(entity Entity) string {
-	encoded, _ := json.Marshal(buildLocalCacheValue(entity))
-	return string(encoded)
+func buildRedisValue(engine *Engine, entity Entity) string {
+	return encodeRedisValue(engine, buildLocalCacheValue(entity))
 }
 
 func buildLocalCacheValue(entity Entity) []string {
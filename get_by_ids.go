@@ -1,7 +1,6 @@
 package orm
 
 import (
-	"encoding/json"
 	"reflect"
 	"strings"
 
@@ -75,7 +74,7 @@ func tryByIDs(engine *Engine, ids []uint64, entities reflect.Value, references [
 				} else {
 					toSet = buildLocalCacheValue(val)
 				}
-				pairs[i+1] = toSet
+				pairs[i+1] = schema.localCacheValue(toSet)
 				i += 2
 			}
 			localCache.MSet(pairs...)
@@ -94,12 +93,16 @@ func tryByIDs(engine *Engine, ids []uint64, entities reflect.Value, references [
 				if val == nil {
 					toSet = "nil"
 				} else {
-					toSet = buildRedisValue(val)
+					toSet = buildRedisValue(engine, val)
 				}
 				pairs[i+1] = toSet
 				i += 2
 			}
-			redisCache.MSet(pairs...)
+			if schema.redisTTL > 0 {
+				redisCache.MSetEx(schema.redisTTL, pairs...)
+			} else {
+				redisCache.MSet(pairs...)
+			}
 		}
 	}
 
@@ -134,9 +137,7 @@ func getKeysForNils(engine *Engine, entityType reflect.Type, rows map[string]int
 				results[k] = nil
 			} else if fromRedis {
 				entity := reflect.New(entityType).Interface().(Entity)
-				var decoded []string
-				_ = json.Unmarshal([]byte(v.(string)), &decoded)
-				fillFromDBRow(keysMapping[k], engine, decoded, entity)
+				fillFromDBRow(keysMapping[k], engine, decodeRedisValue(v.(string)), entity)
 				results[k] = entity
 			} else {
 				entity := reflect.New(entityType).Interface().(Entity)
@@ -10,6 +10,7 @@ type LazyReceiver struct {
 	engine      *Engine
 	disableLoop bool
 	heartBeat   func()
+	workers     receiverWorkers
 }
 
 func NewLazyReceiver(engine *Engine) *LazyReceiver {
@@ -24,25 +25,28 @@ func (r *LazyReceiver) SetHeartBeat(beat func()) {
 	r.heartBeat = beat
 }
 
+// SetWorkers runs many consumers in parallel, lazy queries are no longer executed in the order they were flushed,
+// so use it only when the same rows are not flushed lazily many times in short time
+func (r *LazyReceiver) SetWorkers(workers int) {
+	r.workers.count = workers
+}
+
+func (r *LazyReceiver) Shutdown() {
+	r.workers.shutdown()
+}
+
 func (r *LazyReceiver) Digest() {
-	channel := r.engine.GetRabbitMQQueue(lazyQueueName)
-	consumer := channel.NewConsumer("default consumer")
-	defer consumer.Close()
-	if r.disableLoop {
-		consumer.DisableLoop()
-	}
-	if r.heartBeat != nil {
-		consumer.SetHeartBeat(r.heartBeat)
-	}
-	var data interface{}
-	consumer.Consume(func(items [][]byte) {
-		for _, item := range items {
-			_ = jsoniter.ConfigFastest.Unmarshal(item, &data)
-			validMap := data.(map[string]interface{})
-			r.handleQueries(r.engine, validMap)
-			r.handleClearCache(validMap, "cl")
-			r.handleClearCache(validMap, "cr")
-		}
+	r.workers.run(r.engine, lazyQueueName, r.disableLoop, r.heartBeat, func(engine *Engine, consumer QueueConsumer) {
+		var data interface{}
+		consumer.Consume(func(items [][]byte) {
+			for _, item := range items {
+				_ = jsoniter.ConfigFastest.Unmarshal(item, &data)
+				validMap := data.(map[string]interface{})
+				r.handleQueries(engine, validMap)
+				r.handleClearCache(engine, validMap, "cl")
+				r.handleClearCache(engine, validMap, "cr")
+			}
+		})
 	})
 }
 
@@ -61,7 +65,7 @@ func (r *LazyReceiver) handleQueries(engine *Engine, validMap map[string]interfa
 	}
 }
 
-func (r *LazyReceiver) handleClearCache(validMap map[string]interface{}, key string) {
+func (r *LazyReceiver) handleClearCache(engine *Engine, validMap map[string]interface{}, key string) {
 	keys, has := validMap[key]
 	if has {
 		validKeys := keys.(map[string]interface{})
@@ -72,10 +76,11 @@ func (r *LazyReceiver) handleClearCache(validMap map[string]interface{}, key str
 				stringKeys[i] = v.(string)
 			}
 			if key == "cl" {
-				cache := r.engine.localCache[cacheCode]
+				cache := engine.localCache[cacheCode]
 				cache.Remove(stringKeys...)
+				engine.publishLocalCacheInvalidation(map[string][]string{cacheCode: stringKeys})
 			} else {
-				cache := r.engine.redis[cacheCode]
+				cache := engine.redis[cacheCode]
 				cache.Del(stringKeys...)
 			}
 		}
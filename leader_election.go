@@ -0,0 +1,156 @@
+package orm
+
+import (
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+)
+
+const leaderElectionKeyPrefix = "orm_leader:"
+const counterRedisLeaderElection = "redis.leaderElection"
+
+const leaderElectionCampaignScript = `
+local current = redis.call('GET', KEYS[1])
+if current then
+	local separator = string.find(current, ':', 1, true)
+	if string.sub(current, 1, separator - 1) == ARGV[1] then
+		redis.call('PEXPIRE', KEYS[1], ARGV[2])
+		return tonumber(string.sub(current, separator + 1))
+	end
+	return 0
+end
+local token = redis.call('INCR', KEYS[2])
+redis.call('SET', KEYS[1], ARGV[1] .. ':' .. token, 'PX', ARGV[2])
+return token
+`
+
+const leaderElectionResignScript = `
+local current = redis.call('GET', KEYS[1])
+if current and string.sub(current, 1, string.len(ARGV[1]) + 1) == ARGV[1] .. ':' then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`
+
+const leaderElectionTokenScript = `
+local current = redis.call('GET', KEYS[1])
+if not current then
+	return 0
+end
+return tonumber(string.sub(current, string.find(current, ':', 1, true) + 1))
+`
+
+type Election struct {
+	locker    *Locker
+	name      string
+	id        string
+	ttl       time.Duration
+	onElected func(fencingToken uint64)
+	onRevoked func()
+	mutex     sync.Mutex
+	token     uint64
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+// Elect campaigns for leadership in background until Resign is called.
+// Callbacks are executed in background goroutine, except onRevoked triggered by Resign
+// which is executed in goroutine calling Resign.
+func (l *Locker) Elect(name string, ttl time.Duration, onElected func(fencingToken uint64), onRevoked func()) *Election {
+	if ttl < 3*time.Millisecond {
+		panic(errors.NotValidf("ttl must be at least 3 milliseconds"))
+	}
+	election := &Election{locker: l, name: name, id: newLeaseToken(), ttl: ttl, onElected: onElected, onRevoked: onRevoked,
+		stop: make(chan struct{}), done: make(chan struct{})}
+	go election.run()
+	return election
+}
+
+// CurrentFencingToken returns token of current leader, zero if there is no leader
+func (l *Locker) CurrentFencingToken(name string) uint64 {
+	keys := leaderElectionKeys(name)
+	return uint64(l.evalKeys("[ORM][LOCKER][LEADER_TOKEN]", "leader token", counterRedisLeaderElection, keys, leaderElectionTokenScript))
+}
+
+func (e *Election) IsLeader() bool {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.token > 0
+}
+
+// FencingToken grows with every new leader, zero if instance is not a leader
+func (e *Election) FencingToken() uint64 {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.token
+}
+
+func (e *Election) Resign() {
+	select {
+	case <-e.stop:
+		return
+	default:
+	}
+	close(e.stop)
+	<-e.done
+	_, _ = e.eval("[ORM][LOCKER][LEADER_RESIGN]", "leader resign", leaderElectionResignScript, e.id)
+	e.setToken(0)
+}
+
+func (e *Election) run() {
+	defer close(e.done)
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+	margin := e.ttl / 3
+	lastRenew := time.Now()
+	for {
+		attempt := time.Now()
+		token, err := e.eval("[ORM][LOCKER][LEADER_CAMPAIGN]", "leader campaign", leaderElectionCampaignScript,
+			e.id, e.ttl.Milliseconds())
+		if err == nil {
+			lastRenew = attempt
+			e.setToken(uint64(token))
+		} else if time.Since(lastRenew) >= e.ttl-margin {
+			e.setToken(0)
+		}
+		select {
+		case <-e.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (e *Election) setToken(token uint64) {
+	e.mutex.Lock()
+	before := e.token
+	e.token = token
+	e.mutex.Unlock()
+	if before > 0 && before != token && e.onRevoked != nil {
+		e.onRevoked()
+	}
+	if token > 0 && before != token && e.onElected != nil {
+		e.onElected(token)
+	}
+}
+
+func (e *Election) eval(message string, operation string, script string, args ...interface{}) (int64, error) {
+	keys := leaderElectionKeys(e.name)
+	start := time.Now()
+	res, err := e.locker.engine.GetRedis(e.locker.code).client.Eval(script, keys, args...)
+	if e.locker.engine.queryLoggers[QueryLoggerSourceRedis] != nil {
+		e.locker.fillLogFields(message, start, keys[0], operation, err)
+	}
+	e.locker.engine.dataDog.incrementCounter(counterRedisAll, 1)
+	e.locker.engine.dataDog.incrementCounter(counterRedisLeaderElection, 1)
+	if err != nil {
+		return 0, err
+	}
+	return res.(int64), nil
+}
+
+func leaderElectionKeys(name string) []string {
+	key := leaderElectionKeyPrefix + "{" + name + "}"
+	return []string{key, key + ":token"}
+}
@@ -1,17 +1,21 @@
 package orm
 
 import (
-	"sync"
+	"fmt"
 	"time"
-
-	"github.com/golang/groupcache/lru"
 )
 
 type LocalCache struct {
-	engine *Engine
-	code   string
-	lru    *lru.Cache
-	ttl    int64
+	engine  *Engine
+	code    string
+	storage *localCacheStorage
+	ttl     int64
+	group   *singleFlight
+}
+
+type localCacheValue struct {
+	value   interface{}
+	expires int64
 }
 
 type ttlValue struct {
@@ -27,15 +31,17 @@ func (c *LocalCache) GetSet(key string, ttlSeconds int, provider GetSetProvider)
 			return ttlVal.value
 		}
 	}
-	userVal := provider()
-	val = ttlValue{value: userVal, time: time.Now().Unix()}
-	c.Set(key, val)
+	userVal, _ := c.group.do(key, func() interface{} {
+		userVal := provider()
+		c.Set(key, ttlValue{value: userVal, time: time.Now().Unix()})
+		return userVal
+	})
 	return userVal
 }
 
 func (c *LocalCache) Get(key string) (value interface{}, ok bool) {
 	start := time.Now()
-	value, ok = c.lru.Get(key)
+	value, ok = c.get(key)
 	misses := 0
 	if !ok {
 		misses = 1
@@ -51,7 +57,7 @@ func (c *LocalCache) MGet(keys ...string) map[string]interface{} {
 	results := make(map[string]interface{}, len(keys))
 	misses := 0
 	for _, key := range keys {
-		value, ok := c.lru.Get(key)
+		value, ok := c.get(key)
 		if !ok {
 			misses++
 			value = nil
@@ -66,10 +72,7 @@ func (c *LocalCache) MGet(keys ...string) map[string]interface{} {
 
 func (c *LocalCache) Set(key string, value interface{}) {
 	start := time.Now()
-	m := &sync.Mutex{}
-	m.Lock()
-	defer m.Unlock()
-	c.lru.Add(key, value)
+	c.storage.add(key, c.wrap(value))
 	if c.engine.queryLoggers[QueryLoggerSourceLocalCache] != nil {
 		c.fillLogFields("[ORM][LOCAL][MGET]", start, "set", -1, map[string]interface{}{"Key": key, "value": value})
 	}
@@ -78,11 +81,12 @@ func (c *LocalCache) Set(key string, value interface{}) {
 func (c *LocalCache) MSet(pairs ...interface{}) {
 	start := time.Now()
 	max := len(pairs)
-	m := &sync.Mutex{}
-	m.Lock()
-	defer m.Unlock()
 	for i := 0; i < max; i += 2 {
-		c.lru.Add(pairs[i], pairs[i+1])
+		key, is := pairs[i].(string)
+		if !is {
+			key = fmt.Sprintf("%v", pairs[i])
+		}
+		c.storage.add(key, c.wrap(pairs[i+1]))
 	}
 	if c.engine.queryLoggers[QueryLoggerSourceLocalCache] != nil {
 		c.fillLogFields("[ORM][LOCAL][MSET]", start, "mset", -1, map[string]interface{}{"Keys": pairs})
@@ -93,7 +97,7 @@ func (c *LocalCache) HMget(key string, fields ...string) map[string]interface{}
 	start := time.Now()
 	l := len(fields)
 	results := make(map[string]interface{}, l)
-	value, ok := c.lru.Get(key)
+	value, ok := c.get(key)
 	misses := 0
 	for _, field := range fields {
 		if !ok {
@@ -115,19 +119,37 @@ func (c *LocalCache) HMget(key string, fields ...string) map[string]interface{}
 	return results
 }
 
-func (c *LocalCache) HMset(key string, fields map[string]interface{}) {
+func (c *LocalCache) HGetAll(key string) map[string]interface{} {
 	start := time.Now()
-	m, has := c.lru.Get(key)
-	if !has {
-		m = make(map[string]interface{})
-		mutex := &sync.Mutex{}
-		mutex.Lock()
-		defer mutex.Unlock()
-		c.lru.Add(key, m)
+	value, ok := c.get(key)
+	results := make(map[string]interface{})
+	misses := 1
+	if ok {
+		misses = 0
+		for field, val := range value.(map[string]interface{}) {
+			results[field] = val
+		}
 	}
-	for k, v := range fields {
-		m.(map[string]interface{})[k] = v
+	if c.engine.queryLoggers[QueryLoggerSourceLocalCache] != nil {
+		c.fillLogFields("[ORM][LOCAL][HGETALL]", start, "hgetall", misses, map[string]interface{}{"Key": key})
 	}
+	return results
+}
+
+func (c *LocalCache) HMset(key string, fields map[string]interface{}) {
+	start := time.Now()
+	c.storage.update(key, func(value interface{}, has bool) interface{} {
+		m := make(map[string]interface{}, len(fields))
+		if has {
+			for k, v := range value.(map[string]interface{}) {
+				m[k] = v
+			}
+		}
+		for k, v := range fields {
+			m[k] = v
+		}
+		return c.wrap(m)
+	})
 	if c.engine.queryLoggers[QueryLoggerSourceLocalCache] != nil {
 		c.fillLogFields("[ORM][LOCAL][HMSET]", start, "hmset", -1, map[string]interface{}{"Key": key, "fields": fields})
 	}
@@ -135,12 +157,7 @@ func (c *LocalCache) HMset(key string, fields map[string]interface{}) {
 
 func (c *LocalCache) Remove(keys ...string) {
 	start := time.Now()
-	m := &sync.Mutex{}
-	m.Lock()
-	defer m.Unlock()
-	for _, v := range keys {
-		c.lru.Remove(v)
-	}
+	c.storage.remove(keys...)
 	if c.engine.queryLoggers[QueryLoggerSourceLocalCache] != nil {
 		c.fillLogFields("[ORM][LOCAL][REMOVE]", start, "remove", -1, map[string]interface{}{"Keys": keys})
 	}
@@ -148,15 +165,28 @@ func (c *LocalCache) Remove(keys ...string) {
 
 func (c *LocalCache) Clear() {
 	start := time.Now()
-	m := &sync.Mutex{}
-	m.Lock()
-	defer m.Unlock()
-	c.lru.Clear()
+	c.storage.clear()
 	if c.engine.queryLoggers[QueryLoggerSourceLocalCache] != nil {
 		c.fillLogFields("[ORM][LOCAL][CLEAR]", start, "clear", -1, nil)
 	}
 }
 
+func (c *LocalCache) Stats() LocalCacheStats {
+	return c.storage.stats()
+}
+
+func (c *LocalCache) get(key string) (value interface{}, ok bool) {
+	return c.storage.get(key)
+}
+
+func (c *LocalCache) wrap(value interface{}) interface{} {
+	_, is := value.(localCacheValue)
+	if is || c.ttl <= 0 {
+		return value
+	}
+	return localCacheValue{value: value, expires: time.Now().Unix() + c.ttl}
+}
+
 func (c *LocalCache) fillLogFields(message string, start time.Time, operation string, misses int, fields map[string]interface{}) {
 	stop := time.Since(start).Microseconds()
 	e := c.engine.queryLoggers[QueryLoggerSourceLocalCache].log.
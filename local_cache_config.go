@@ -1,11 +1,8 @@
 package orm
 
-import (
-	"github.com/golang/groupcache/lru"
-)
-
 type LocalCacheConfig struct {
-	code string
-	lru  *lru.Cache
-	ttl  int64
+	code    string
+	storage *localCacheStorage
+	ttl     int64
+	group   singleFlight
 }
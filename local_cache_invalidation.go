@@ -0,0 +1,82 @@
+package orm
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+const localCacheInvalidationQueueName = "orm_local_cache_invalidation"
+const localCacheInvalidationRouterName = "orm_local_cache_invalidation_router"
+
+type localCacheInvalidationValue struct {
+	Instance string              `json:"i"`
+	Keys     map[string][]string `json:"k"`
+}
+
+type LocalCacheInvalidationReceiver struct {
+	engine      *Engine
+	disableLoop bool
+	heartBeat   func()
+}
+
+func NewLocalCacheInvalidationReceiver(engine *Engine) *LocalCacheInvalidationReceiver {
+	return &LocalCacheInvalidationReceiver{engine: engine}
+}
+
+func (r *LocalCacheInvalidationReceiver) DisableLoop() {
+	r.disableLoop = true
+}
+
+func (r *LocalCacheInvalidationReceiver) SetHeartBeat(beat func()) {
+	r.heartBeat = beat
+}
+
+func (r *LocalCacheInvalidationReceiver) Digest() {
+	channel := r.engine.GetRabbitMQRouter(localCacheInvalidationQueueName)
+	consumer := channel.NewConsumer("default consumer")
+	defer consumer.Close()
+	if r.disableLoop {
+		consumer.DisableLoop()
+	}
+	if r.heartBeat != nil {
+		consumer.SetHeartBeat(r.heartBeat)
+	}
+	consumer.Consume(func(items [][]byte) {
+		for _, item := range items {
+			var value localCacheInvalidationValue
+			_ = jsoniter.ConfigFastest.Unmarshal(item, &value)
+			if value.Instance == r.engine.registry.localCacheInvalidationInstance {
+				continue
+			}
+			for cacheCode, keys := range value.Keys {
+				cache, has := r.engine.localCache[cacheCode]
+				if has {
+					cache.Remove(keys...)
+				}
+			}
+		}
+	})
+}
+
+func (e *Engine) publishLocalCacheInvalidation(keys map[string][]string) {
+	if len(keys) == 0 || e.registry.localCacheInvalidationInstance == "" {
+		return
+	}
+	value := &localCacheInvalidationValue{Instance: e.registry.localCacheInvalidationInstance, Keys: keys}
+	asJSON, _ := jsoniter.ConfigFastest.Marshal(value)
+	e.GetRabbitMQRouter(localCacheInvalidationQueueName).Publish("", asJSON)
+}
+
+func addLocalCacheInvalidation(invalidated map[string][]string, cacheCode string, pairs []interface{}) {
+	for i := 0; i < len(pairs); i += 2 {
+		invalidated[cacheCode] = append(invalidated[cacheCode], pairs[i].(string))
+	}
+}
+
+func newInstanceID() string {
+	hostname, _ := os.Hostname()
+	return fmt.Sprintf("%s_%d_%d", hostname, os.Getpid(), time.Now().UnixNano())
+}
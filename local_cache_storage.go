@@ -0,0 +1,150 @@
+package orm
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/groupcache/lru"
+	"github.com/segmentio/fasthash/fnv1a"
+)
+
+const localCacheMaxShards = 16
+const localCacheMinShardSize = 64
+
+type LocalCacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Size      int
+}
+
+type localCacheShard struct {
+	sync.Mutex
+	lru      *lru.Cache
+	removing bool
+}
+
+type localCacheStorage struct {
+	hits      uint64
+	misses    uint64
+	evictions uint64
+	shards    []*localCacheShard
+}
+
+func newLocalCacheStorage(size int) *localCacheStorage {
+	shardsCount := size / localCacheMinShardSize
+	if shardsCount < 1 {
+		shardsCount = 1
+	} else if shardsCount > localCacheMaxShards {
+		shardsCount = localCacheMaxShards
+	}
+	shardSize := size / shardsCount
+	if shardSize < 1 {
+		shardSize = 1
+	}
+	storage := &localCacheStorage{shards: make([]*localCacheShard, shardsCount)}
+	for i := 0; i < shardsCount; i++ {
+		shard := &localCacheShard{lru: lru.New(shardSize)}
+		shard.lru.OnEvicted = func(key lru.Key, value interface{}) {
+			if !shard.removing {
+				atomic.AddUint64(&storage.evictions, 1)
+			}
+		}
+		storage.shards[i] = shard
+	}
+	return storage
+}
+
+func (s *localCacheStorage) getShard(key string) *localCacheShard {
+	if len(s.shards) == 1 {
+		return s.shards[0]
+	}
+	return s.shards[fnv1a.HashString32(key)%uint32(len(s.shards))]
+}
+
+func (s *localCacheStorage) get(key string) (value interface{}, ok bool) {
+	shard := s.getShard(key)
+	shard.Lock()
+	value, ok = shard.lru.Get(key)
+	if ok {
+		withTTL, is := value.(localCacheValue)
+		if is {
+			value = withTTL.value
+			if withTTL.expires < time.Now().Unix() {
+				shard.lru.Remove(key)
+				value = nil
+				ok = false
+			}
+		}
+	}
+	shard.Unlock()
+	if ok {
+		atomic.AddUint64(&s.hits, 1)
+	} else {
+		atomic.AddUint64(&s.misses, 1)
+	}
+	return value, ok
+}
+
+func (s *localCacheStorage) add(key string, value interface{}) {
+	shard := s.getShard(key)
+	shard.Lock()
+	shard.lru.Add(key, value)
+	shard.Unlock()
+}
+
+func (s *localCacheStorage) update(key string, modifier func(value interface{}, has bool) interface{}) {
+	shard := s.getShard(key)
+	shard.Lock()
+	defer shard.Unlock()
+	value, has := shard.lru.Get(key)
+	if has {
+		withTTL, is := value.(localCacheValue)
+		if is {
+			if withTTL.expires < time.Now().Unix() {
+				value = nil
+				has = false
+			} else {
+				value = withTTL.value
+			}
+		}
+	}
+	shard.lru.Add(key, modifier(value, has))
+}
+
+func (s *localCacheStorage) remove(keys ...string) {
+	for _, key := range keys {
+		shard := s.getShard(key)
+		shard.Lock()
+		shard.removing = true
+		shard.lru.Remove(key)
+		shard.removing = false
+		shard.Unlock()
+	}
+}
+
+func (s *localCacheStorage) clear() {
+	for _, shard := range s.shards {
+		shard.Lock()
+		shard.removing = true
+		shard.lru.Clear()
+		shard.removing = false
+		shard.Unlock()
+	}
+}
+
+func (s *localCacheStorage) stats() LocalCacheStats {
+	size := 0
+	for _, shard := range s.shards {
+		shard.Lock()
+		size += shard.lru.Len()
+		shard.Unlock()
+	}
+	return LocalCacheStats{
+		Hits:      atomic.LoadUint64(&s.hits),
+		Misses:    atomic.LoadUint64(&s.misses),
+		Evictions: atomic.LoadUint64(&s.evictions),
+		Size:      size,
+	}
+}
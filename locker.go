@@ -1,6 +1,9 @@
 package orm
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
 	"time"
 
 	"github.com/juju/errors"
@@ -11,6 +14,7 @@ import (
 const counterRedisLockObtain = "redis.lockObtain"
 const counterRedisLockRelease = "redis.lockRelease"
 const counterRedisLockTTL = "redis.lockTTL"
+const counterRedisLockRefresh = "redis.lockRefresh"
 
 type lockerClient interface {
 	Obtain(key string, ttl time.Duration, opt *redislock.Options) (*redislock.Lock, error)
@@ -59,21 +63,27 @@ func (l *Locker) Obtain(key string, ttl time.Duration, waitTimeout time.Duration
 	}
 	l.engine.dataDog.incrementCounter(counterRedisAll, 1)
 	l.engine.dataDog.incrementCounter(counterRedisLockObtain, 1)
-	return &Lock{lock: redisLock, locker: l, key: key, has: true, engine: l.engine}, true
+	return &Lock{lock: redisLock, locker: l, key: key, has: true, engine: l.engine, lost: make(chan struct{})}, true
 }
 
 type Lock struct {
-	lock   *redislock.Lock
-	key    string
-	locker *Locker
-	has    bool
-	engine *Engine
+	lock     *redislock.Lock
+	key      string
+	locker   *Locker
+	has      bool
+	engine   *Engine
+	mutex    sync.Mutex
+	lost     chan struct{}
+	watchdog chan struct{}
 }
 
 func (l *Lock) Release() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
 	if !l.has {
 		return
 	}
+	l.stopWatchdog()
 	start := time.Now()
 	err := l.lock.Release()
 	if l.engine.queryLoggers[QueryLoggerSourceRedis] != nil {
@@ -85,6 +95,8 @@ func (l *Lock) Release() {
 }
 
 func (l *Lock) TTL() time.Duration {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
 	start := time.Now()
 	d, err := l.lock.TTL()
 	if l.engine.queryLoggers[QueryLoggerSourceRedis] != nil {
@@ -95,9 +107,113 @@ func (l *Lock) TTL() time.Duration {
 	if err != nil {
 		panic(err)
 	}
+	if d == 0 && l.has {
+		l.markLost()
+	}
 	return d
 }
 
+// Lost returns channel closed when lock expired or was taken by someone else before Release
+func (l *Lock) Lost() <-chan struct{} {
+	return l.lost
+}
+
+func (l *Lock) Refresh(ttl time.Duration) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	refreshed, err := l.refresh(ttl)
+	if err != nil {
+		panic(err)
+	}
+	return refreshed
+}
+
+// StartWatchdog refreshes lock with given ttl in background until Release is called or lock is lost.
+// Lock is reported as lost when it was not refreshed for two thirds of ttl, before it expires in Redis.
+func (l *Lock) StartWatchdog(ttl time.Duration) {
+	if ttl <= 0 {
+		panic(errors.NotValidf("ttl must be greater than zero"))
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if !l.has || l.watchdog != nil {
+		return
+	}
+	stop := make(chan struct{})
+	l.watchdog = stop
+	go func() {
+		ticker := time.NewTicker(ttl / 3)
+		defer ticker.Stop()
+		lastRefresh := time.Now()
+		margin := ttl / 3
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				l.mutex.Lock()
+				if l.watchdog != stop {
+					l.mutex.Unlock()
+					return
+				}
+				attempt := time.Now()
+				refreshed, err := l.refresh(ttl)
+				if err == nil {
+					lastRefresh = attempt
+				} else if time.Since(lastRefresh) >= ttl-margin {
+					l.markLost()
+				}
+				l.mutex.Unlock()
+				if err == nil && !refreshed {
+					return
+				}
+			}
+		}
+	}()
+}
+
+func (l *Lock) StopWatchdog() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.stopWatchdog()
+}
+
+func (l *Lock) stopWatchdog() {
+	if l.watchdog != nil {
+		close(l.watchdog)
+		l.watchdog = nil
+	}
+}
+
+func (l *Lock) refresh(ttl time.Duration) (refreshed bool, err error) {
+	if !l.has {
+		return false, nil
+	}
+	start := time.Now()
+	err = l.lock.Refresh(ttl, nil)
+	if err == redislock.ErrNotObtained {
+		err = nil
+		l.markLost()
+	} else if err == nil {
+		refreshed = true
+	}
+	if l.engine.queryLoggers[QueryLoggerSourceRedis] != nil {
+		l.locker.fillLogFields("[ORM][LOCKER][REFRESH]", start, l.key, "refresh lock", err)
+	}
+	l.engine.dataDog.incrementCounter(counterRedisAll, 1)
+	l.engine.dataDog.incrementCounter(counterRedisLockRefresh, 1)
+	return refreshed, err
+}
+
+func (l *Lock) markLost() {
+	if !l.has {
+		return
+	}
+	l.has = false
+	l.stopWatchdog()
+	close(l.lost)
+}
+
 func (l *Locker) fillLogFields(message string, start time.Time, key string, operation string, err error) {
 	now := time.Now()
 	stop := time.Since(start).Microseconds()
@@ -115,3 +231,95 @@ func (l *Locker) fillLogFields(message string, start time.Time, key string, oper
 		e.Info(message)
 	}
 }
+
+// leaseScriptNow reads current time from Redis server, so leases never depend on clocks of application hosts
+const leaseScriptNow = `
+redis.replicate_commands()
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+`
+
+type redisLease struct {
+	locker        *Locker
+	key           string
+	member        string
+	has           bool
+	mutex         sync.Mutex
+	refreshScript string
+	releaseScript string
+}
+
+func (l *redisLease) Release() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if !l.has {
+		return
+	}
+	l.locker.eval("[ORM][LOCKER][RELEASE]", "release lock", counterRedisLockRelease, l.key, l.releaseScript, l.member)
+	l.has = false
+}
+
+func (l *redisLease) Refresh(ttl time.Duration) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if !l.has {
+		return false
+	}
+	refreshed := l.locker.eval("[ORM][LOCKER][REFRESH]", "refresh lock", counterRedisLockRefresh, l.key, l.refreshScript,
+		l.member, ttl.Milliseconds()) == 1
+	if !refreshed {
+		l.has = false
+	}
+	return refreshed
+}
+
+func (l *Locker) obtainLease(message string, key string, ttl time.Duration, waitTimeout time.Duration, script string, args ...interface{}) bool {
+	if ttl <= 0 {
+		panic(errors.NotValidf("ttl must be greater than zero"))
+	}
+	if waitTimeout == 0 {
+		waitTimeout = ttl
+	}
+	backoff := 16 * time.Millisecond
+	deadline := time.Now().Add(waitTimeout)
+	for {
+		scriptArgs := append([]interface{}{ttl.Milliseconds()}, args...)
+		if l.eval(message, "obtain lock", counterRedisLockObtain, key, script, scriptArgs...) == 1 {
+			return true
+		}
+		if time.Now().Add(backoff).After(deadline) {
+			return false
+		}
+		time.Sleep(backoff)
+		if backoff < 256*time.Millisecond {
+			backoff *= 2
+		}
+	}
+}
+
+func (l *Locker) eval(message string, operation string, counter string, key string, script string, args ...interface{}) int64 {
+	return l.evalKeys(message, operation, counter, []string{key}, script, args...)
+}
+
+func (l *Locker) evalKeys(message string, operation string, counter string, keys []string, script string, args ...interface{}) int64 {
+	start := time.Now()
+	res, err := l.engine.GetRedis(l.code).client.Eval(script, keys, args...)
+	if l.engine.queryLoggers[QueryLoggerSourceRedis] != nil {
+		l.fillLogFields(message, start, keys[0], operation, err)
+	}
+	l.engine.dataDog.incrementCounter(counterRedisAll, 1)
+	l.engine.dataDog.incrementCounter(counter, 1)
+	if err != nil {
+		panic(err)
+	}
+	return res.(int64)
+}
+
+func newLeaseToken() string {
+	token := make([]byte, 16)
+	_, err := rand.Read(token)
+	if err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(token)
+}
@@ -35,3 +35,174 @@ func TestLocker(t *testing.T) {
 	lock.Release()
 	lock.Release()
 }
+
+type testLogNotifier struct {
+	message string
+	notify  chan struct{}
+}
+
+func (h *testLogNotifier) HandleLog(entry *log2.Entry) error {
+	if entry.Message == h.message {
+		select {
+		case h.notify <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+func TestLockerRefresh(t *testing.T) {
+	registry := &Registry{}
+	registry.RegisterRedis("localhost:6380", 5)
+	registry.RegisterLocker("default", "default")
+	validatedRegistry, err := registry.Validate()
+	assert.Nil(t, err)
+	engine := validatedRegistry.CreateEngine()
+	engine.GetRedis().FlushDB()
+	locker := engine.GetLocker()
+
+	lock, has := locker.Obtain("test_refresh", time.Second, 0)
+	assert.True(t, has)
+	assert.True(t, lock.Refresh(5*time.Second))
+	assert.True(t, lock.TTL() > time.Second)
+	lock.Release()
+	assert.False(t, lock.Refresh(5*time.Second))
+
+	refreshes := &testLogNotifier{message: "[ORM][LOCKER][REFRESH]", notify: make(chan struct{}, 100)}
+	engine.AddQueryLogger(refreshes, log2.InfoLevel, QueryLoggerSourceRedis)
+	lock, has = locker.Obtain("test_refresh", 300*time.Millisecond, 0)
+	assert.True(t, has)
+	lock.StartWatchdog(300 * time.Millisecond)
+	for i := 0; i < 5; i++ {
+		<-refreshes.notify
+	}
+	_, has = locker.Obtain("test_refresh", time.Second, 100*time.Millisecond)
+	assert.False(t, has)
+	lock.Release()
+	_, has = locker.Obtain("test_refresh", time.Second, 100*time.Millisecond)
+	assert.True(t, has)
+
+	lock, has = locker.Obtain("test_lost", time.Second, 0)
+	assert.True(t, has)
+	engine.GetRedis().Del("test_lost")
+	assert.False(t, lock.Refresh(time.Second))
+	select {
+	case <-lock.Lost():
+	default:
+		assert.Fail(t, "lock lost not notified")
+	}
+
+	lock, has = locker.Obtain("test_lost_watchdog", 300*time.Millisecond, 0)
+	assert.True(t, has)
+	lock.StartWatchdog(300 * time.Millisecond)
+	engine.GetRedis().Del("test_lost_watchdog")
+	select {
+	case <-lock.Lost():
+	case <-time.After(time.Second):
+		assert.Fail(t, "lock lost not notified")
+	}
+}
+
+func TestLockerRWLockAndSemaphore(t *testing.T) {
+	registry := &Registry{}
+	registry.RegisterRedis("localhost:6380", 5)
+	registry.RegisterLocker("default", "default")
+	validatedRegistry, err := registry.Validate()
+	assert.Nil(t, err)
+	engine := validatedRegistry.CreateEngine()
+	engine.GetRedis().FlushDB()
+	locker := engine.GetLocker()
+	testLogger := memory.New()
+	engine.AddQueryLogger(testLogger, log2.InfoLevel, QueryLoggerSourceRedis)
+
+	reader1, has := locker.ObtainRead("rw", time.Second, 0)
+	assert.True(t, has)
+	assert.Equal(t, "[ORM][LOCKER][OBTAIN_READ]", testLogger.Entries[0].Message)
+	reader2, has := locker.ObtainRead("rw", time.Second, 0)
+	assert.True(t, has)
+	_, has = locker.ObtainWrite("rw", time.Second, 100*time.Millisecond)
+	assert.False(t, has)
+	_, has = locker.ObtainRead("rw", time.Second, 100*time.Millisecond)
+	assert.False(t, has)
+	assert.True(t, reader1.Refresh(2*time.Second))
+	reader1.Release()
+	reader2.Release()
+	assert.False(t, reader1.Refresh(time.Second))
+	writer, has := locker.ObtainWrite("rw", time.Second, 0)
+	assert.True(t, has)
+	_, has = locker.ObtainRead("rw", time.Second, 100*time.Millisecond)
+	assert.False(t, has)
+	_, has = locker.ObtainWrite("rw", time.Second, 100*time.Millisecond)
+	assert.False(t, has)
+	writer.Release()
+	_, has = locker.ObtainRead("rw", 200*time.Millisecond, 0)
+	assert.True(t, has)
+	_, has = locker.ObtainWrite("rw", time.Second, time.Second)
+	assert.True(t, has)
+
+	permit1, has := locker.AcquireSemaphore("api", 2, time.Second, 0)
+	assert.True(t, has)
+	_, has = locker.AcquireSemaphore("api", 2, 200*time.Millisecond, 0)
+	assert.True(t, has)
+	_, has = locker.AcquireSemaphore("api", 2, time.Second, 100*time.Millisecond)
+	assert.False(t, has)
+	permit1.Release()
+	permit3, has := locker.AcquireSemaphore("api", 2, time.Second, 0)
+	assert.True(t, has)
+	assert.True(t, permit3.Refresh(time.Second))
+	_, has = locker.AcquireSemaphore("api", 2, time.Second, time.Second)
+	assert.True(t, has)
+	assert.PanicsWithError(t, "ttl must be greater than zero not valid", func() {
+		locker.AcquireSemaphore("api", 2, -time.Second, 0)
+	})
+}
+
+func TestLockerElect(t *testing.T) {
+	registry := &Registry{}
+	registry.RegisterRedis("localhost:6380", 5)
+	registry.RegisterLocker("default", "default")
+	validatedRegistry, err := registry.Validate()
+	assert.Nil(t, err)
+	engine := validatedRegistry.CreateEngine()
+	engine.GetRedis().FlushDB()
+	locker := engine.GetLocker()
+	campaigns := &testLogNotifier{message: "[ORM][LOCKER][LEADER_CAMPAIGN]", notify: make(chan struct{})}
+	engine.AddQueryLogger(campaigns, log2.InfoLevel, QueryLoggerSourceRedis)
+
+	elected := make(chan uint64, 10)
+	revoked := make(chan bool, 10)
+	first := locker.Elect("cron", 300*time.Millisecond, func(fencingToken uint64) {
+		elected <- fencingToken
+	}, func() {
+		revoked <- true
+	})
+	assert.Equal(t, uint64(1), <-elected)
+	assert.True(t, first.IsLeader())
+	assert.Equal(t, uint64(1), first.FencingToken())
+
+	secondElected := make(chan uint64, 10)
+	second := locker.Elect("cron", 300*time.Millisecond, func(fencingToken uint64) {
+		secondElected <- fencingToken
+	}, nil)
+	for i := 0; i < 6; i++ {
+		<-campaigns.notify
+	}
+	assert.False(t, second.IsLeader())
+	assert.True(t, first.IsLeader())
+	assert.Equal(t, uint64(1), locker.CurrentFencingToken("cron"))
+
+	first.Resign()
+	assert.True(t, <-revoked)
+	assert.False(t, first.IsLeader())
+	select {
+	case token := <-secondElected:
+		assert.Equal(t, uint64(2), token)
+	case <-time.After(time.Second):
+		assert.Fail(t, "leader not elected")
+	}
+	assert.True(t, second.IsLeader())
+	assert.Equal(t, uint64(2), locker.CurrentFencingToken("cron"))
+	second.Resign()
+	second.Resign()
+	assert.Equal(t, uint64(0), locker.CurrentFencingToken("cron"))
+}
@@ -0,0 +1,118 @@
+package orm
+
+import (
+	"sync"
+	"time"
+)
+
+type memoryQueueProvider struct {
+	mutex  sync.Mutex
+	queues map[string]*memoryQueue
+}
+
+func NewMemoryQueueProvider() QueueProvider {
+	return &memoryQueueProvider{queues: make(map[string]*memoryQueue)}
+}
+
+func (p *memoryQueueProvider) GetQueue(_ *Engine, queueName string, prefetchCount int) Queue {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	queue, has := p.queues[queueName]
+	if !has {
+		if prefetchCount <= 0 {
+			prefetchCount = 1
+		}
+		queue = &memoryQueue{prefetchCount: prefetchCount, notify: make(chan struct{}, 1)}
+		p.queues[queueName] = queue
+	}
+	return queue
+}
+
+type memoryQueue struct {
+	mutex         sync.Mutex
+	messages      [][]byte
+	prefetchCount int
+	notify        chan struct{}
+}
+
+func (q *memoryQueue) Publish(body []byte) {
+	q.mutex.Lock()
+	q.messages = append(q.messages, body)
+	q.mutex.Unlock()
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (q *memoryQueue) NewConsumer(_ string) QueueConsumer {
+	return &memoryQueueConsumer{queue: q, stop: make(chan struct{})}
+}
+
+func (q *memoryQueue) pop() [][]byte {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	max := q.prefetchCount
+	if max > len(q.messages) {
+		max = len(q.messages)
+	}
+	items := q.messages[:max:max]
+	q.messages = q.messages[max:]
+	return items
+}
+
+func (q *memoryQueue) restore(items [][]byte) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.messages = append(items, q.messages...)
+}
+
+type memoryQueueConsumer struct {
+	queue       *memoryQueue
+	stop        chan struct{}
+	disableLoop sync.Once
+	heartBeat   func()
+}
+
+func (c *memoryQueueConsumer) Close() {
+}
+
+func (c *memoryQueueConsumer) DisableLoop() {
+	c.disableLoop.Do(func() {
+		close(c.stop)
+	})
+}
+
+func (c *memoryQueueConsumer) SetHeartBeat(beat func()) {
+	c.heartBeat = beat
+}
+
+func (c *memoryQueueConsumer) Consume(handler func(items [][]byte)) {
+	for {
+		items := c.queue.pop()
+		if len(items) > 0 {
+			c.handle(handler, items)
+			continue
+		}
+		select {
+		case <-c.stop:
+			return
+		case <-c.queue.notify:
+		case <-time.After(time.Minute):
+			if c.heartBeat != nil {
+				c.heartBeat()
+			}
+		}
+	}
+}
+
+func (c *memoryQueueConsumer) handle(handler func(items [][]byte), items [][]byte) {
+	handled := false
+	defer func() {
+		if !handled {
+			c.queue.restore(items)
+		}
+	}()
+	handler(items)
+	handled = true
+}
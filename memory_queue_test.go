@@ -0,0 +1,64 @@
+package orm
+
+import (
+	"testing"
+
+	log2 "github.com/apex/log"
+	"github.com/apex/log/handlers/memory"
+	"github.com/stretchr/testify/assert"
+)
+
+type testEntityMemoryQueue struct {
+	ORM  `orm:"localCache"`
+	ID   uint
+	Name string
+}
+
+func TestMemoryQueue(t *testing.T) {
+	provider := NewMemoryQueueProvider()
+	queue := provider.GetQueue(nil, "test", 2)
+	assert.Equal(t, queue, provider.GetQueue(nil, "test", 2))
+	queue.Publish([]byte("a"))
+	queue.Publish([]byte("b"))
+	queue.Publish([]byte("c"))
+
+	consumer := queue.NewConsumer("test consumer")
+	consumer.DisableLoop()
+	assert.Panics(t, func() {
+		consumer.Consume(func(items [][]byte) {
+			panic("stop")
+		})
+	})
+	batches := make([][][]byte, 0)
+	consumer.Consume(func(items [][]byte) {
+		batches = append(batches, items)
+	})
+	assert.Equal(t, [][][]byte{{[]byte("a"), []byte("b")}, {[]byte("c")}}, batches)
+
+	batches = batches[:0]
+	consumer.Consume(func(items [][]byte) {
+		batches = append(batches, items)
+	})
+	assert.Len(t, batches, 0)
+}
+
+func TestMemoryQueueLazyFlush(t *testing.T) {
+	var entity testEntityMemoryQueue
+	registry := &Registry{}
+	registry.RegisterQueueProvider(NewMemoryQueueProvider())
+	engine := PrepareTables(t, registry, entity)
+
+	DBLogger := memory.New()
+	engine.AddQueryLogger(DBLogger, log2.InfoLevel, QueryLoggerSourceDB)
+	engine.Track(&testEntityMemoryQueue{Name: "a"})
+	engine.FlushLazy()
+	assert.Len(t, DBLogger.Entries, 0)
+
+	receiver := NewLazyReceiver(engine)
+	receiver.DisableLoop()
+	receiver.Digest()
+	assert.Len(t, DBLogger.Entries, 1)
+	found := engine.LoadByID(1, &entity)
+	assert.True(t, found)
+	assert.Equal(t, "a", entity.Name)
+}
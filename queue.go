@@ -0,0 +1,59 @@
+package orm
+
+type QueueConsumer interface {
+	Close()
+	Consume(handler func(items [][]byte))
+	DisableLoop()
+	SetHeartBeat(beat func())
+}
+
+type Queue interface {
+	Publish(body []byte)
+	NewConsumer(name string) QueueConsumer
+}
+
+type BatchQueue interface {
+	PublishBatch(bodies [][]byte)
+}
+
+type QueueProvider interface {
+	GetQueue(engine *Engine, queueName string, prefetchCount int) Queue
+}
+
+type rabbitMQQueueProvider struct {
+}
+
+func (p *rabbitMQQueueProvider) GetQueue(engine *Engine, queueName string, _ int) Queue {
+	return &rabbitMQORMQueue{engine.GetRabbitMQQueue(queueName)}
+}
+
+type rabbitMQORMQueue struct {
+	queue *RabbitMQQueue
+}
+
+func (q *rabbitMQORMQueue) Publish(body []byte) {
+	q.queue.Publish(body)
+}
+
+func (q *rabbitMQORMQueue) PublishBatch(bodies [][]byte) {
+	q.queue.PublishBatch(bodies)
+}
+
+func (q *rabbitMQORMQueue) NewConsumer(name string) QueueConsumer {
+	return q.queue.NewConsumer(name)
+}
+
+func (e *Engine) getQueue(queueName string) Queue {
+	return e.registry.queueProvider.GetQueue(e, queueName, e.registry.queues[queueName])
+}
+
+func publishBatch(queue Queue, bodies [][]byte) {
+	batch, is := queue.(BatchQueue)
+	if is {
+		batch.PublishBatch(bodies)
+		return
+	}
+	for _, body := range bodies {
+		queue.Publish(body)
+	}
+}
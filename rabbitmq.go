@@ -1,7 +1,10 @@
 package orm
 
 import (
+	"fmt"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/juju/errors"
@@ -17,30 +20,128 @@ const counterRabbitMQACK = "rabbitMQ.ack"
 const counterRabbitMQConnect = "rabbitMQ.connect"
 const counterRabbitMQCreateChannel = "rabbitMQ.createChannel"
 const counterRabbitMQRegister = "rabbitMQ.register"
+const counterRabbitMQRetry = "rabbitMQ.retry"
+const counterRabbitMQNack = "rabbitMQ.nack"
+const counterRabbitMQConfirm = "rabbitMQ.confirm"
+const rabbitMQDefaultConfirmTimeout = time.Second * 5
+const rabbitMQConfirmBatchSize = 1000
+const rabbitMQRetriesHeader = "x-retries"
 
 type rabbitMQConfig struct {
-	code    string
-	address string
+	code           string
+	address        string
+	confirm        bool
+	confirmTimeout time.Duration
 }
 
 type RabbitMQConsumer interface {
 	Close()
 	Consume(handler func(items [][]byte))
+	ConsumeDeliveries(handler func(deliveries []*RabbitMQDelivery))
 	DisableLoop()
 	SetHeartBeat(beat func())
 }
 
+type RabbitMQPublishOptions struct {
+	Headers       map[string]interface{}
+	ContentType   string
+	Persistent    bool
+	Priority      uint8
+	CorrelationID string
+	ReplyTo       string
+	Expiration    time.Duration
+	MessageID     string
+	Type          string
+	AppID         string
+}
+
+type RabbitMQDelivery struct {
+	Body          []byte
+	Headers       map[string]interface{}
+	ContentType   string
+	Persistent    bool
+	Priority      uint8
+	CorrelationID string
+	ReplyTo       string
+	Expiration    string
+	MessageID     string
+	Timestamp     time.Time
+	Type          string
+	AppID         string
+	Exchange      string
+	RoutingKey    string
+	Redelivered   bool
+	Retries       int
+	processed     bool
+}
+
+// MarkProcessed tells consumer that message was handled, so it is acknowledged and not handled again
+// when handler panics later in the same batch
+func (d *RabbitMQDelivery) MarkProcessed() {
+	d.processed = true
+}
+
+func newRabbitMQDelivery(delivery *amqp.Delivery) *RabbitMQDelivery {
+	return &RabbitMQDelivery{
+		Body:          delivery.Body,
+		Headers:       delivery.Headers,
+		ContentType:   delivery.ContentType,
+		Persistent:    delivery.DeliveryMode == amqp.Persistent,
+		Priority:      delivery.Priority,
+		CorrelationID: delivery.CorrelationId,
+		ReplyTo:       delivery.ReplyTo,
+		Expiration:    delivery.Expiration,
+		MessageID:     delivery.MessageId,
+		Timestamp:     delivery.Timestamp,
+		Type:          delivery.Type,
+		AppID:         delivery.AppId,
+		Exchange:      delivery.Exchange,
+		RoutingKey:    delivery.RoutingKey,
+		Redelivered:   delivery.Redelivered,
+		Retries:       rabbitMQDeliveryRetries(delivery),
+	}
+}
+
+func newRabbitMQPublishing(body []byte, options *RabbitMQPublishOptions) amqp.Publishing {
+	msg := amqp.Publishing{ContentType: "text/plain", Body: body}
+	if options == nil {
+		return msg
+	}
+	if options.ContentType != "" {
+		msg.ContentType = options.ContentType
+	}
+	if len(options.Headers) > 0 {
+		msg.Headers = amqp.Table{}
+		for k, v := range options.Headers {
+			msg.Headers[k] = v
+		}
+	}
+	if options.Persistent {
+		msg.DeliveryMode = amqp.Persistent
+	}
+	if options.Expiration > 0 {
+		msg.Expiration = strconv.FormatInt(options.Expiration.Milliseconds(), 10)
+	}
+	msg.Priority = options.Priority
+	msg.CorrelationId = options.CorrelationID
+	msg.ReplyTo = options.ReplyTo
+	msg.MessageId = options.MessageID
+	msg.Type = options.Type
+	msg.AppId = options.AppID
+	return msg
+}
+
 type rabbitMQReceiver struct {
 	name            string
 	channel         *amqp.Channel
 	parent          *rabbitMQChannel
-	disableLoop     bool
+	disableLoop     int32
 	maxLoopDuration time.Duration
 	heartBeat       func()
 }
 
 func (r *rabbitMQReceiver) DisableLoop() {
-	r.disableLoop = true
+	atomic.StoreInt32(&r.disableLoop, 1)
 }
 
 func (r *rabbitMQReceiver) SetMaxLoopDuration(duration time.Duration) {
@@ -60,6 +161,8 @@ func (r *rabbitMQReceiver) Close() {
 	}
 	r.parent.engine.dataDog.incrementCounter(counterRabbitMQAll, 1)
 	r.parent.engine.dataDog.incrementCounter(counterRabbitMQCloseChannel, 1)
+	r.parent.connection.muxConsumer.Lock()
+	defer r.parent.connection.muxConsumer.Unlock()
 	delete(r.parent.connection.channelConsumers, r.parent.config.Name)
 }
 
@@ -68,6 +171,16 @@ func (r *rabbitMQReceiver) consume() (<-chan amqp.Delivery, error) {
 }
 
 func (r *rabbitMQReceiver) Consume(handler func(items [][]byte)) {
+	r.ConsumeDeliveries(func(deliveries []*RabbitMQDelivery) {
+		items := make([][]byte, len(deliveries))
+		for i, delivery := range deliveries {
+			items[i] = delivery.Body
+		}
+		handler(items)
+	})
+}
+
+func (r *rabbitMQReceiver) ConsumeDeliveries(handler func(deliveries []*RabbitMQDelivery)) {
 	delivery, err := r.consume()
 	if err != nil {
 		panic(err)
@@ -79,36 +192,31 @@ func (r *rabbitMQReceiver) Consume(handler func(items [][]byte)) {
 		max = 1
 	}
 	counter := 0
-	var last *amqp.Delivery
-	items := make([][]byte, 0)
+	deliveries := make([]amqp.Delivery, 0)
+	items := make([]*RabbitMQDelivery, 0)
 	ticker := time.NewTicker(time.Minute)
 	for {
 		if counter > 0 && (timeOut || counter == max) {
-			handler(items)
-			items = nil
-			start := time.Now()
-			err = last.Ack(true)
-			if r.parent.engine.queryLoggers[QueryLoggerSourceRabbitMQ] != nil {
-				fillRabbitMQLogFields(r.parent.engine, "[ORM][RABBIT_MQ][ACK]", start, "ack",
-					map[string]interface{}{"Queue": r.parent.config.Name, "consumer": r.name}, err)
-			}
-			r.parent.engine.dataDog.incrementCounter(counterRabbitMQAll, 1)
-			r.parent.engine.dataDog.incrementCounter(counterRabbitMQACK, 1)
-			if err != nil {
-				panic(err)
+			if r.parent.config.MaxRetries > 0 || r.parent.config.DeadLetter {
+				r.handleWithRetries(handler, items, deliveries)
+			} else {
+				handler(items)
+				r.ack(&deliveries[len(deliveries)-1], true)
 			}
+			items = nil
+			deliveries = nil
 			counter = 0
 			timeOut = false
-			if r.disableLoop {
+			if atomic.LoadInt32(&r.disableLoop) == 1 {
 				return
 			}
-		} else if timeOut && r.disableLoop {
+		} else if timeOut && atomic.LoadInt32(&r.disableLoop) == 1 {
 			return
 		}
 		select {
 		case item := <-delivery:
-			last = &item
-			items = append(items, item.Body)
+			deliveries = append(deliveries, item)
+			items = append(items, newRabbitMQDelivery(&item))
 			counter++
 			r.parent.engine.dataDog.incrementCounter(counterRabbitMQAll, 1)
 			r.parent.engine.dataDog.incrementCounter(counterRabbitMQReceive, 1)
@@ -122,14 +230,123 @@ func (r *rabbitMQReceiver) Consume(handler func(items [][]byte)) {
 	}
 }
 
+func (r *rabbitMQReceiver) handleWithRetries(handler func(deliveries []*RabbitMQDelivery), items []*RabbitMQDelivery,
+	deliveries []amqp.Delivery) {
+	err := callRabbitMQHandler(handler, items)
+	if err == nil {
+		r.ack(&deliveries[len(deliveries)-1], true)
+		return
+	}
+	//messages not marked as processed are handled again one by one, so only failing messages are retried
+	for i, item := range items {
+		itemErr := err
+		if !item.processed && len(items) > 1 {
+			itemErr = callRabbitMQHandler(handler, items[i:i+1])
+		}
+		if item.processed || itemErr == nil {
+			r.ack(&deliveries[i], false)
+		} else {
+			r.fail(&deliveries[i], itemErr)
+		}
+	}
+}
+
+func (r *rabbitMQReceiver) ack(delivery *amqp.Delivery, multiple bool) {
+	start := time.Now()
+	err := delivery.Ack(multiple)
+	if r.parent.engine.queryLoggers[QueryLoggerSourceRabbitMQ] != nil {
+		fillRabbitMQLogFields(r.parent.engine, "[ORM][RABBIT_MQ][ACK]", start, "ack",
+			map[string]interface{}{"Queue": r.parent.config.Name, "consumer": r.name}, err)
+	}
+	r.parent.engine.dataDog.incrementCounter(counterRabbitMQAll, 1)
+	r.parent.engine.dataDog.incrementCounter(counterRabbitMQACK, 1)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (r *rabbitMQReceiver) fail(delivery *amqp.Delivery, cause error) {
+	config := r.parent.config
+	retries := rabbitMQDeliveryRetries(delivery)
+	if retries < config.MaxRetries {
+		headers := amqp.Table{}
+		for k, v := range delivery.Headers {
+			headers[k] = v
+		}
+		headers[rabbitMQRetriesHeader] = int64(retries + 1)
+		exchange := ""
+		if config.RetryDelay > 0 {
+			exchange = config.Name + "_retry"
+			headers["x-delay"] = config.RetryDelay.Milliseconds()
+		}
+		msg := amqp.Publishing{Headers: headers, ContentType: delivery.ContentType, DeliveryMode: delivery.DeliveryMode,
+			Priority: delivery.Priority, CorrelationId: delivery.CorrelationId, ReplyTo: delivery.ReplyTo,
+			Expiration: delivery.Expiration, MessageId: delivery.MessageId, Timestamp: delivery.Timestamp,
+			Type: delivery.Type, AppId: delivery.AppId, Body: delivery.Body}
+		start := time.Now()
+		err := r.channel.Publish(exchange, config.Name, false, false, msg)
+		if r.parent.engine.queryLoggers[QueryLoggerSourceRabbitMQ] != nil {
+			fillRabbitMQLogFields(r.parent.engine, "[ORM][RABBIT_MQ][RETRY]", start, "retry",
+				map[string]interface{}{"Queue": config.Name, "consumer": r.name, "retry": retries + 1, "cause": cause.Error()}, err)
+		}
+		r.parent.engine.dataDog.incrementCounter(counterRabbitMQAll, 1)
+		r.parent.engine.dataDog.incrementCounter(counterRabbitMQRetry, 1)
+		if err != nil {
+			panic(err)
+		}
+		r.ack(delivery, false)
+		return
+	}
+	start := time.Now()
+	err := delivery.Nack(false, false)
+	if r.parent.engine.queryLoggers[QueryLoggerSourceRabbitMQ] != nil {
+		fillRabbitMQLogFields(r.parent.engine, "[ORM][RABBIT_MQ][NACK]", start, "nack",
+			map[string]interface{}{"Queue": config.Name, "consumer": r.name, "retries": retries, "cause": cause.Error()}, err)
+	}
+	r.parent.engine.dataDog.incrementCounter(counterRabbitMQAll, 1)
+	r.parent.engine.dataDog.incrementCounter(counterRabbitMQNack, 1)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func rabbitMQDeliveryRetries(delivery *amqp.Delivery) int {
+	switch retries := delivery.Headers[rabbitMQRetriesHeader].(type) {
+	case int64:
+		return int(retries)
+	case int32:
+		return int(retries)
+	case int:
+		return retries
+	}
+	return 0
+}
+
+func callRabbitMQHandler(handler func(deliveries []*RabbitMQDelivery), items []*RabbitMQDelivery) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			asErr, is := rec.(error)
+			if !is {
+				asErr = fmt.Errorf("%v", rec)
+			}
+			err = asErr
+		}
+	}()
+	handler(items)
+	return nil
+}
+
 type rabbitMQConnection struct {
 	config           *rabbitMQConfig
 	clientSender     *amqp.Connection
 	clientReceivers  *amqp.Connection
 	channelSender    *amqp.Channel
+	channelConfirm   *amqp.Channel
+	confirmations    chan amqp.Confirmation
 	channelConsumers map[string]RabbitMQConsumer
 	muxConsumer      sync.Mutex
 	muxSender        sync.Once
+	muxConfirm       sync.Mutex
 }
 
 type rabbitMQChannelToQueue struct {
@@ -181,6 +398,11 @@ type RabbitMQQueueConfig struct {
 	Durable       bool
 	RouterKeys    []string
 	AutoDelete    bool
+	MaxRetries    int
+	RetryDelay    time.Duration
+	DeadLetter    bool
+	Confirm       bool
+	MaxPriority   uint8
 }
 
 type RabbitMQRouterConfig struct {
@@ -191,10 +413,33 @@ type RabbitMQRouterConfig struct {
 
 func (r *rabbitMQChannel) registerQueue(channel *amqp.Channel, name string) (*amqp.Queue, error) {
 	config := r.config
-	q, err := channel.QueueDeclare(name, config.Durable, config.AutoDelete, false, false, nil)
+	args := amqp.Table{}
+	if config.DeadLetter {
+		_, err := channel.QueueDeclare(name+"_dead_letter", true, false, false, false, nil)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		args["x-dead-letter-exchange"] = ""
+		args["x-dead-letter-routing-key"] = name + "_dead_letter"
+	}
+	if config.MaxPriority > 0 {
+		args["x-max-priority"] = config.MaxPriority
+	}
+	q, err := channel.QueueDeclare(name, config.Durable, config.AutoDelete, false, false, args)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	if config.MaxRetries > 0 && config.RetryDelay > 0 {
+		err = channel.ExchangeDeclare(name+"_retry", "x-delayed-message", true, false, false, false,
+			amqp.Table{"x-delayed-type": "direct"})
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		err = channel.QueueBind(name, name, name+"_retry", false, nil)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
 	err = channel.Qos(config.PrefetchCount, 0, false)
 	if err != nil {
 		return nil, errors.Trace(err)
@@ -207,11 +452,25 @@ type RabbitMQQueue struct {
 }
 
 func (r *RabbitMQQueue) Publish(body []byte) {
-	msg := amqp.Publishing{
-		ContentType: "text/plain",
-		Body:        body,
+	r.PublishWithOptions(body, nil)
+}
+
+func (r *RabbitMQQueue) PublishWithOptions(body []byte, options *RabbitMQPublishOptions) {
+	r.publish(false, false, r.config.Name, newRabbitMQPublishing(body, options))
+}
+
+func (r *RabbitMQQueue) PublishBatch(bodies [][]byte) {
+	r.PublishBatchWithOptions(bodies, nil)
+}
+
+func (r *RabbitMQQueue) PublishBatchWithOptions(bodies [][]byte, options *RabbitMQPublishOptions) {
+	keys := make([]string, len(bodies))
+	messages := make([]amqp.Publishing, len(bodies))
+	for i, body := range bodies {
+		keys[i] = r.config.Name
+		messages[i] = newRabbitMQPublishing(body, options)
 	}
-	r.publish(false, false, r.config.Name, msg)
+	r.publishBatch(keys, messages)
 }
 
 type RabbitMQDelayedQueue struct {
@@ -219,13 +478,17 @@ type RabbitMQDelayedQueue struct {
 }
 
 func (r *RabbitMQDelayedQueue) Publish(delayed time.Duration, body []byte) {
-	msg := amqp.Publishing{
-		DeliveryMode: amqp.Persistent,
-		Timestamp:    time.Now(),
-		Headers:      amqp.Table{"x-delay": delayed.Milliseconds()},
-		ContentType:  "text/plain",
-		Body:         body,
+	r.PublishWithOptions(delayed, body, nil)
+}
+
+func (r *RabbitMQDelayedQueue) PublishWithOptions(delayed time.Duration, body []byte, options *RabbitMQPublishOptions) {
+	msg := newRabbitMQPublishing(body, options)
+	msg.DeliveryMode = amqp.Persistent
+	msg.Timestamp = time.Now()
+	if msg.Headers == nil {
+		msg.Headers = amqp.Table{}
 	}
+	msg.Headers["x-delay"] = delayed.Milliseconds()
 	r.publish(false, false, r.config.Name, msg)
 }
 
@@ -234,11 +497,25 @@ type RabbitMQRouter struct {
 }
 
 func (r *RabbitMQRouter) Publish(routerKey string, body []byte) {
-	msg := amqp.Publishing{
-		ContentType: "text/plain",
-		Body:        body,
+	r.PublishWithOptions(routerKey, body, nil)
+}
+
+func (r *RabbitMQRouter) PublishWithOptions(routerKey string, body []byte, options *RabbitMQPublishOptions) {
+	r.publish(false, false, routerKey, newRabbitMQPublishing(body, options))
+}
+
+func (r *RabbitMQRouter) PublishBatch(routerKey string, bodies [][]byte) {
+	r.PublishBatchWithOptions(routerKey, bodies, nil)
+}
+
+func (r *RabbitMQRouter) PublishBatchWithOptions(routerKey string, bodies [][]byte, options *RabbitMQPublishOptions) {
+	keys := make([]string, len(bodies))
+	messages := make([]amqp.Publishing, len(bodies))
+	for i, body := range bodies {
+		keys[i] = routerKey
+		messages[i] = newRabbitMQPublishing(body, options)
 	}
-	r.publish(false, false, routerKey, msg)
+	r.publishBatch(keys, messages)
 }
 
 type rabbitMQChannel struct {
@@ -364,6 +641,10 @@ func (r *rabbitMQChannel) initChannelSender() {
 }
 
 func (r *rabbitMQChannel) publish(mandatory, immediate bool, routingKey string, msg amqp.Publishing) {
+	if r.confirmEnabled() {
+		r.publishConfirmed([]string{routingKey}, []amqp.Publishing{msg})
+		return
+	}
 	if r.connection.channelSender == nil {
 		r.initChannelSender()
 	}
@@ -380,6 +661,101 @@ func (r *rabbitMQChannel) publish(mandatory, immediate bool, routingKey string,
 			}
 		}
 	}
+	r.logPublish(start, routingKey, err)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (r *rabbitMQChannel) publishBatch(routingKeys []string, messages []amqp.Publishing) {
+	if !r.confirmEnabled() {
+		for i, msg := range messages {
+			r.publish(false, false, routingKeys[i], msg)
+		}
+		return
+	}
+	for i := 0; i < len(messages); i += rabbitMQConfirmBatchSize {
+		end := i + rabbitMQConfirmBatchSize
+		if end > len(messages) {
+			end = len(messages)
+		}
+		r.publishConfirmed(routingKeys[i:end], messages[i:end])
+	}
+}
+
+func (r *rabbitMQChannel) confirmEnabled() bool {
+	return r.config.Confirm || r.connection.config.confirm
+}
+
+func (r *rabbitMQChannel) publishConfirmed(routingKeys []string, messages []amqp.Publishing) {
+	if len(messages) == 0 {
+		return
+	}
+	connection := r.connection
+	connection.muxConfirm.Lock()
+	defer connection.muxConfirm.Unlock()
+	if connection.channelConfirm == nil {
+		channel := r.initChannel(r.config.Name, true)
+		err := channel.Confirm(false)
+		if err != nil {
+			_ = channel.Close()
+			panic(err)
+		}
+		connection.confirmations = channel.NotifyPublish(make(chan amqp.Confirmation, rabbitMQConfirmBatchSize))
+		connection.channelConfirm = channel
+	}
+	for i, msg := range messages {
+		start := time.Now()
+		err := connection.channelConfirm.Publish(r.config.Router, routingKeys[i], false, false, msg)
+		r.logPublish(start, routingKeys[i], err)
+		if err != nil {
+			connection.resetConfirmChannel()
+			panic(err)
+		}
+	}
+	start := time.Now()
+	err := r.waitForConfirms(len(messages))
+	if r.engine.queryLoggers[QueryLoggerSourceRabbitMQ] != nil {
+		fillRabbitMQLogFields(r.engine, "[ORM][RABBIT_MQ][CONFIRM]", start, "confirm",
+			map[string]interface{}{"Queue": r.config.Name, "messages": len(messages)}, err)
+	}
+	r.engine.dataDog.incrementCounter(counterRabbitMQAll, 1)
+	r.engine.dataDog.incrementCounter(counterRabbitMQConfirm, 1)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (r *rabbitMQChannel) waitForConfirms(total int) error {
+	timeout := r.connection.config.confirmTimeout
+	if timeout <= 0 {
+		timeout = rabbitMQDefaultConfirmTimeout
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	nacked := 0
+	for i := 0; i < total; i++ {
+		select {
+		case confirmation, ok := <-r.connection.confirmations:
+			if !ok {
+				r.connection.resetConfirmChannel()
+				return errors.Errorf("rabbitMQ channel closed before %d messages were confirmed", total-i)
+			}
+			if !confirmation.Ack {
+				nacked++
+			}
+		case <-timer.C:
+			r.connection.resetConfirmChannel()
+			return errors.Timeoutf("rabbitMQ confirm of %d messages", total-i)
+		}
+	}
+	if nacked > 0 {
+		return errors.Errorf("rabbitMQ rejected %d of %d messages", nacked, total)
+	}
+	return nil
+}
+
+func (r *rabbitMQChannel) logPublish(start time.Time, routingKey string, err error) {
 	if r.engine.queryLoggers[QueryLoggerSourceRabbitMQ] != nil {
 		if r.config.Router != "" {
 			fillRabbitMQLogFields(r.engine, "[ORM][RABBIT_MQ][PUBLISH]", start, "publish",
@@ -391,9 +767,14 @@ func (r *rabbitMQChannel) publish(mandatory, immediate bool, routingKey string,
 	}
 	r.engine.dataDog.incrementCounter(counterRabbitMQAll, 1)
 	r.engine.dataDog.incrementCounter(counterRabbitMQPublish, 1)
-	if err != nil {
-		panic(err)
+}
+
+func (r *rabbitMQConnection) resetConfirmChannel() {
+	if r.channelConfirm != nil {
+		_ = r.channelConfirm.Close()
 	}
+	r.channelConfirm = nil
+	r.confirmations = nil
 }
 
 func fillRabbitMQLogFields(engine *Engine, message string, start time.Time, operation string, fields map[string]interface{}, err error) {
@@ -0,0 +1,162 @@
+package orm
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/juju/errors"
+)
+
+const counterRedisRateLimit = "redis.rateLimit"
+const rateLimiterKeyPrefix = "orm_rate_limit:"
+
+const rateLimiterFixedWindowScript = `
+local current = redis.call('INCR', KEYS[1])
+local ttl = redis.call('PTTL', KEYS[1])
+if ttl < 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+	ttl = tonumber(ARGV[1])
+end
+return {current, ttl}
+`
+
+const rateLimiterSlidingWindowScript = `
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+if count < limit then
+	redis.call('ZADD', KEYS[1], now, ARGV[4])
+	redis.call('PEXPIRE', KEYS[1], window)
+	return {1, limit - count - 1, 0}
+end
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+return {0, 0, tonumber(oldest[2]) + window - now}
+`
+
+const rateLimiterTokenBucketScript = `
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local cost = tonumber(ARGV[4])
+local data = redis.call('HMGET', KEYS[1], 't', 'ts')
+local tokens = tonumber(data[1]) or burst
+local ts = tonumber(data[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate)
+local allowed = 0
+local wait = 0
+if tokens >= cost then
+	tokens = tokens - cost
+	allowed = 1
+else
+	wait = math.ceil((cost - tokens) / rate)
+end
+redis.call('HMSET', KEYS[1], 't', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst / rate))
+return {allowed, math.floor(tokens), wait}
+`
+
+type RateLimitResult struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration
+}
+
+type RateLimiter struct {
+	code   string
+	engine *Engine
+	redis  *RedisCache
+}
+
+func (e *Engine) GetRateLimiter(redisPool ...string) *RateLimiter {
+	redis := e.GetRedis(redisPool...)
+	return &RateLimiter{code: redis.code, engine: e, redis: redis}
+}
+
+func (l *RateLimiter) FixedWindow(key string, limit int, window time.Duration) *RateLimitResult {
+	checkRateLimiterArguments(limit, window)
+	res := l.eval("[ORM][RATE_LIMITER][FIXED_WINDOW]", "fixed window", key, rateLimiterFixedWindowScript,
+		window.Milliseconds())
+	current := int(res[0])
+	result := &RateLimitResult{Allowed: current <= limit}
+	if result.Allowed {
+		result.Remaining = limit - current
+	} else {
+		result.RetryAfter = time.Duration(res[1]) * time.Millisecond
+	}
+	return result
+}
+
+func (l *RateLimiter) SlidingWindow(key string, limit int, window time.Duration) *RateLimitResult {
+	checkRateLimiterArguments(limit, window)
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	member := fmt.Sprintf("%d-%d", now, rand.Int63())
+	res := l.eval("[ORM][RATE_LIMITER][SLIDING_WINDOW]", "sliding window", key, rateLimiterSlidingWindowScript,
+		now, window.Milliseconds(), limit, member)
+	return &RateLimitResult{Allowed: res[0] == 1, Remaining: int(res[1]), RetryAfter: time.Duration(res[2]) * time.Millisecond}
+}
+
+func (l *RateLimiter) TokenBucket(key string, perSecond float64, burst int, cost int) *RateLimitResult {
+	if perSecond <= 0 || burst <= 0 {
+		panic(errors.NotValidf("rate and burst must be greater than zero"))
+	}
+	if cost <= 0 {
+		panic(errors.NotValidf("cost must be greater than zero"))
+	}
+	if cost > burst {
+		panic(errors.NotValidf("cost %d is greater than burst %d", cost, burst))
+	}
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	res := l.eval("[ORM][RATE_LIMITER][TOKEN_BUCKET]", "token bucket", key, rateLimiterTokenBucketScript,
+		perSecond/1000, burst, now, cost)
+	return &RateLimitResult{Allowed: res[0] == 1, Remaining: int(res[1]), RetryAfter: time.Duration(res[2]) * time.Millisecond}
+}
+
+func (l *RateLimiter) eval(message string, operation string, key string, script string, args ...interface{}) []int64 {
+	start := time.Now()
+	val, err := l.redis.client.Eval(script, []string{rateLimiterKeyPrefix + key}, args...)
+	if l.engine.queryLoggers[QueryLoggerSourceRedis] != nil {
+		l.fillLogFields(message, start, key, operation, args, err)
+	}
+	l.engine.dataDog.incrementCounter(counterRedisAll, 1)
+	l.engine.dataDog.incrementCounter(counterRedisRateLimit, 1)
+	if err != nil {
+		panic(err)
+	}
+	values := val.([]interface{})
+	res := make([]int64, len(values))
+	for i, v := range values {
+		res[i] = v.(int64)
+	}
+	return res
+}
+
+func checkRateLimiterArguments(limit int, window time.Duration) {
+	if limit <= 0 {
+		panic(errors.NotValidf("limit must be greater than zero"))
+	}
+	if window < time.Millisecond {
+		panic(errors.NotValidf("window must be at least one millisecond"))
+	}
+}
+
+func (l *RateLimiter) fillLogFields(message string, start time.Time, key string, operation string, args []interface{}, err error) {
+	now := time.Now()
+	stop := time.Since(start).Microseconds()
+	e := l.engine.queryLoggers[QueryLoggerSourceRedis].log.
+		WithField("Key", key).
+		WithField("args", args).
+		WithField("microseconds", stop).
+		WithField("operation", operation).
+		WithField("pool", l.code).
+		WithField("target", "rate limiter").
+		WithField("started", start.UnixNano()).
+		WithField("finished", now.UnixNano())
+	if err != nil {
+		injectLogError(err, e).Error(message)
+	} else {
+		e.Info(message)
+	}
+}
@@ -0,0 +1,61 @@
+package orm
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	log2 "github.com/apex/log"
+	"github.com/apex/log/handlers/memory"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter(t *testing.T) {
+	_, engine := prepareRedis(t)
+	testLogger := memory.New()
+	engine.AddQueryLogger(testLogger, log2.InfoLevel, QueryLoggerSourceRedis)
+	limiter := engine.GetRateLimiter()
+
+	for i := 2; i >= 0; i-- {
+		result := limiter.FixedWindow("fixed", 3, time.Second)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, i, result.Remaining)
+	}
+	result := limiter.FixedWindow("fixed", 3, time.Second)
+	assert.False(t, result.Allowed)
+	assert.True(t, result.RetryAfter > 0 && result.RetryAfter <= time.Second)
+	assert.Equal(t, "[ORM][RATE_LIMITER][FIXED_WINDOW]", testLogger.Entries[0].Message)
+	time.Sleep(result.RetryAfter + 10*time.Millisecond)
+	assert.True(t, limiter.FixedWindow("fixed", 3, time.Second).Allowed)
+
+	assert.True(t, limiter.SlidingWindow("sliding", 2, 500*time.Millisecond).Allowed)
+	time.Sleep(300 * time.Millisecond)
+	assert.True(t, limiter.SlidingWindow("sliding", 2, 500*time.Millisecond).Allowed)
+	result = limiter.SlidingWindow("sliding", 2, 500*time.Millisecond)
+	assert.False(t, result.Allowed)
+	assert.True(t, result.RetryAfter > 0 && result.RetryAfter <= 200*time.Millisecond)
+	time.Sleep(result.RetryAfter + 10*time.Millisecond)
+	assert.True(t, limiter.SlidingWindow("sliding", 2, 500*time.Millisecond).Allowed)
+
+	result = limiter.TokenBucket("bucket", 10, 5, 5)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+	result = limiter.TokenBucket("bucket", 10, 5, 1)
+	assert.False(t, result.Allowed)
+	assert.True(t, result.RetryAfter > 0 && result.RetryAfter <= 100*time.Millisecond)
+	time.Sleep(result.RetryAfter + 10*time.Millisecond)
+	assert.True(t, limiter.TokenBucket("bucket", 10, 5, 1).Allowed)
+
+	assert.PanicsWithError(t, "limit must be greater than zero not valid", func() {
+		limiter.FixedWindow("fixed", 0, time.Second)
+	})
+	assert.PanicsWithError(t, fmt.Sprintf("cost %d is greater than burst %d not valid", 6, 5), func() {
+		limiter.TokenBucket("bucket", 10, 5, 6)
+	})
+	assert.PanicsWithError(t, "cost must be greater than zero not valid", func() {
+		limiter.TokenBucket("bucket", 10, 5, 0)
+	})
+	assert.PanicsWithError(t, "cost must be greater than zero not valid", func() {
+		limiter.TokenBucket("bucket", 10, 5, -1)
+	})
+}
@@ -0,0 +1,107 @@
+package orm
+
+import (
+	"fmt"
+	"sync"
+)
+
+type receiverWorkers struct {
+	count   int
+	mutex   sync.Mutex
+	stopped bool
+	runs    map[chan struct{}][]QueueConsumer
+}
+
+func (w *receiverWorkers) run(engine *Engine, queueName string, disableLoop bool, heartBeat func(),
+	digest func(engine *Engine, consumer QueueConsumer)) {
+	total := w.count
+	if total < 1 {
+		total = 1
+	}
+	engines := make([]*Engine, total)
+	consumers := make([]QueueConsumer, total)
+	done := make(chan struct{})
+	if heartBeat != nil && total > 1 {
+		beat := heartBeat
+		var heartBeatMutex sync.Mutex
+		heartBeat = func() {
+			heartBeatMutex.Lock()
+			defer heartBeatMutex.Unlock()
+			beat()
+		}
+	}
+	w.mutex.Lock()
+	for i := 0; i < total; i++ {
+		engines[i] = engine
+		name := "default consumer"
+		if i > 0 {
+			engines[i] = engine.newWorkerEngine()
+			name = fmt.Sprintf("default consumer %d", i)
+		}
+		consumers[i] = engines[i].getQueue(queueName).NewConsumer(name)
+		if disableLoop || w.stopped {
+			consumers[i].DisableLoop()
+		}
+		if heartBeat != nil {
+			consumers[i].SetHeartBeat(heartBeat)
+		}
+	}
+	if w.runs == nil {
+		w.runs = make(map[chan struct{}][]QueueConsumer)
+	}
+	w.runs[done] = consumers
+	w.mutex.Unlock()
+	defer func() {
+		w.mutex.Lock()
+		delete(w.runs, done)
+		w.mutex.Unlock()
+		close(done)
+	}()
+
+	if total == 1 {
+		defer consumers[0].Close()
+		digest(engine, consumers[0])
+		return
+	}
+	var wg sync.WaitGroup
+	var once sync.Once
+	var panicValue interface{}
+	for i := 0; i < total; i++ {
+		wg.Add(1)
+		go func(engine *Engine, consumer QueueConsumer) {
+			defer wg.Done()
+			defer consumer.Close()
+			defer func() {
+				if rec := recover(); rec != nil {
+					once.Do(func() {
+						panicValue = rec
+						for _, other := range consumers {
+							other.DisableLoop()
+						}
+					})
+				}
+			}()
+			digest(engine, consumer)
+		}(engines[i], consumers[i])
+	}
+	wg.Wait()
+	if panicValue != nil {
+		panic(panicValue)
+	}
+}
+
+func (w *receiverWorkers) shutdown() {
+	w.mutex.Lock()
+	w.stopped = true
+	runs := make([]chan struct{}, 0, len(w.runs))
+	for done, consumers := range w.runs {
+		for _, consumer := range consumers {
+			consumer.DisableLoop()
+		}
+		runs = append(runs, done)
+	}
+	w.mutex.Unlock()
+	for _, done := range runs {
+		<-done
+	}
+}
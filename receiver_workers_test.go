@@ -0,0 +1,63 @@
+package orm
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReceiverWorkers(t *testing.T) {
+	registry := &Registry{}
+	registry.RegisterQueueProvider(NewMemoryQueueProvider())
+	validatedRegistry, err := registry.Validate()
+	assert.Nil(t, err)
+	engine := validatedRegistry.CreateEngine()
+	engine.SetLogMetaData("source", "test")
+
+	queue := engine.getQueue(lazyQueueName)
+	for i := 0; i < 10; i++ {
+		queue.Publish([]byte("a"))
+	}
+	workers := &receiverWorkers{count: 3}
+	var mutex sync.Mutex
+	engines := make(map[*Engine]bool)
+	received := 0
+	processed := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		workers.run(engine, lazyQueueName, false, nil, func(engine *Engine, consumer QueueConsumer) {
+			assert.Equal(t, "test", engine.logMetaData["source"])
+			engine.dataDog.incrementCounter("test", 1)
+			mutex.Lock()
+			engines[engine] = true
+			mutex.Unlock()
+			consumer.Consume(func(items [][]byte) {
+				mutex.Lock()
+				received += len(items)
+				if received == 10 {
+					close(processed)
+				}
+				mutex.Unlock()
+			})
+		})
+		close(done)
+	}()
+	<-processed
+	workers.shutdown()
+	<-done
+	assert.Len(t, engines, 3)
+	assert.True(t, engines[engine])
+	assert.Equal(t, uint(3), engine.dataDog.counters["test"])
+	assert.Len(t, workers.runs, 0)
+
+	queue.Publish([]byte("b"))
+	workers = &receiverWorkers{count: 2}
+	assert.PanicsWithValue(t, "stop", func() {
+		workers.run(engine, lazyQueueName, true, nil, func(engine *Engine, consumer QueueConsumer) {
+			consumer.Consume(func(items [][]byte) {
+				panic("stop")
+			})
+		})
+	})
+}
@@ -1,6 +1,9 @@
 package orm
 
 import (
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	jsoniter "github.com/json-iterator/go"
@@ -11,6 +14,7 @@ import (
 const counterRedisAll = "redis.all"
 const counterRedisKeysSet = "redis.keysSet"
 const counterRedisKeysGet = "redis.keysGet"
+const stampedeLockRetryInterval = 20 * time.Millisecond
 
 type redisClient interface {
 	Get(key string) (string, error)
@@ -35,195 +39,364 @@ type redisClient interface {
 	HSet(key string, field string, value interface{}) (int64, error)
 	MGet(keys ...string) ([]interface{}, error)
 	Set(key string, value interface{}, expiration time.Duration) error
+	SetNX(key string, value interface{}, expiration time.Duration) (bool, error)
 	MSet(pairs ...interface{}) error
+	MSetEx(expiration time.Duration, pairs ...interface{}) error
 	Del(keys ...string) error
+	Eval(script string, keys []string, args ...interface{}) (interface{}, error)
 	FlushDB() error
+	Incr(key string) (int64, error)
+	IncrBy(key string, value int64) (int64, error)
+	HIncrBy(key, field string, incr int64) (int64, error)
+	HDel(key string, fields ...string) (int64, error)
+	Expire(key string, expiration time.Duration) (bool, error)
+	TTL(key string) (time.Duration, error)
+	Exists(keys ...string) (int64, error)
+	ZRangeByScore(key string, opt *redis.ZRangeBy) ([]string, error)
+	ZRem(key string, members ...interface{}) (int64, error)
+	ZRevRange(key string, start, stop int64) ([]string, error)
+	SMembers(key string) ([]string, error)
+	SIsMember(key string, member interface{}) (bool, error)
+	Scan(cursor uint64, match string, count int64) ([]string, uint64, error)
+	XAdd(a *redis.XAddArgs) (string, error)
+	XLen(stream string) (int64, error)
+	XGroupCreateMkStream(stream, group, start string) (string, error)
+	XGroupDelConsumer(stream, group, consumer string) (int64, error)
+	XReadGroup(a *redis.XReadGroupArgs) ([]redis.XStream, error)
+	XAck(stream, group string, ids ...string) (int64, error)
+	XPendingExt(a *redis.XPendingExtArgs) ([]redis.XPendingExt, error)
+	XClaim(a *redis.XClaimArgs) ([]redis.XMessage, error)
+	Pipelined(fn func(redis.Pipeliner) error) ([]redis.Cmder, error)
+	TxPipelined(fn func(redis.Pipeliner) error) ([]redis.Cmder, error)
 }
 
 type standardRedisClient struct {
-	client *redis.Client
-	ring   *redis.Ring
+	client  redis.UniversalClient
+	cluster *redis.ClusterClient
 }
 
 func (c *standardRedisClient) Get(key string) (string, error) {
-	if c.ring != nil {
-		return c.ring.Get(key).Result()
-	}
 	return c.client.Get(key).Result()
 }
 
 func (c *standardRedisClient) LRange(key string, start, stop int64) ([]string, error) {
-	if c.ring != nil {
-		return c.ring.LRange(key, start, stop).Result()
-	}
 	return c.client.LRange(key, start, stop).Result()
 }
 
 func (c *standardRedisClient) HMGet(key string, fields ...string) ([]interface{}, error) {
-	if c.ring != nil {
-		return c.ring.HMGet(key, fields...).Result()
-	}
 	return c.client.HMGet(key, fields...).Result()
 }
 
 func (c *standardRedisClient) HGetAll(key string) (map[string]string, error) {
-	if c.ring != nil {
-		return c.ring.HGetAll(key).Result()
-	}
 	return c.client.HGetAll(key).Result()
 }
 
 func (c *standardRedisClient) LPush(key string, values ...interface{}) (int64, error) {
-	if c.ring != nil {
-		return c.ring.LPush(key, values...).Result()
-	}
 	return c.client.LPush(key, values...).Result()
 }
 
 func (c *standardRedisClient) RPush(key string, values ...interface{}) (int64, error) {
-	if c.ring != nil {
-		return c.ring.RPush(key, values...).Result()
-	}
 	return c.client.RPush(key, values...).Result()
 }
 
 func (c *standardRedisClient) RPop(key string) (string, error) {
-	if c.ring != nil {
-		return c.ring.RPop(key).Result()
-	}
 	return c.client.RPop(key).Result()
 }
 
 func (c *standardRedisClient) LSet(key string, index int64, value interface{}) (string, error) {
-	if c.ring != nil {
-		return c.ring.LSet(key, index, value).Result()
-	}
 	return c.client.LSet(key, index, value).Result()
 }
 
 func (c *standardRedisClient) LRem(key string, count int64, value interface{}) (int64, error) {
-	if c.ring != nil {
-		return c.ring.LRem(key, count, value).Result()
-	}
 	return c.client.LRem(key, count, value).Result()
 }
 
 func (c *standardRedisClient) LTrim(key string, start, stop int64) (string, error) {
-	if c.ring != nil {
-		return c.ring.LTrim(key, start, stop).Result()
-	}
 	return c.client.LTrim(key, start, stop).Result()
 }
 
 func (c *standardRedisClient) ZCard(key string) (int64, error) {
-	if c.ring != nil {
-		return c.ring.ZCard(key).Result()
-	}
 	return c.client.ZCard(key).Result()
 }
 
 func (c *standardRedisClient) SCard(key string) (int64, error) {
-	if c.ring != nil {
-		return c.ring.SCard(key).Result()
-	}
 	return c.client.SCard(key).Result()
 }
 
 func (c *standardRedisClient) ZCount(key string, min, max string) (int64, error) {
-	if c.ring != nil {
-		return c.ring.ZCount(key, min, max).Result()
-	}
 	return c.client.ZCount(key, min, max).Result()
 }
 
 func (c *standardRedisClient) SPop(key string) (string, error) {
-	if c.ring != nil {
-		return c.ring.SPop(key).Result()
-	}
 	return c.client.SPop(key).Result()
 }
 
 func (c *standardRedisClient) SPopN(key string, max int64) ([]string, error) {
-	if c.ring != nil {
-		return c.ring.SPopN(key, max).Result()
-	}
 	return c.client.SPopN(key, max).Result()
 }
 
 func (c *standardRedisClient) LLen(key string) (int64, error) {
-	if c.ring != nil {
-		return c.ring.LLen(key).Result()
-	}
 	return c.client.LLen(key).Result()
 }
 
 func (c *standardRedisClient) ZAdd(key string, members ...*redis.Z) (int64, error) {
-	if c.ring != nil {
-		return c.ring.ZAdd(key, members...).Result()
-	}
 	return c.client.ZAdd(key, members...).Result()
 }
 
 func (c *standardRedisClient) SAdd(key string, members ...interface{}) (int64, error) {
-	if c.ring != nil {
-		return c.ring.SAdd(key, members...).Result()
-	}
 	return c.client.SAdd(key, members...).Result()
 }
 
 func (c *standardRedisClient) HMSet(key string, fields map[string]interface{}) (bool, error) {
-	if c.ring != nil {
-		return c.ring.HMSet(key, fields).Result()
-	}
 	return c.client.HMSet(key, fields).Result()
 }
 
 func (c *standardRedisClient) HSet(key string, field string, value interface{}) (int64, error) {
-	if c.ring != nil {
-		return c.ring.HSet(key, field, value).Result()
-	}
 	return c.client.HSet(key, field, value).Result()
 }
 
 func (c *standardRedisClient) MGet(keys ...string) ([]interface{}, error) {
-	if c.ring != nil {
-		return c.ring.MGet(keys...).Result()
+	if c.cluster == nil {
+		return c.client.MGet(keys...).Result()
+	}
+	slots, positions := groupRedisKeysBySlot(keys)
+	commands := make([]*redis.SliceCmd, len(slots))
+	_, err := c.cluster.Pipelined(func(pipeline redis.Pipeliner) error {
+		for i, slotKeys := range slots {
+			commands[i] = pipeline.MGet(slotKeys...)
+		}
+		return nil
+	})
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+	results := make([]interface{}, len(keys))
+	for i, command := range commands {
+		for j, value := range command.Val() {
+			results[positions[i][j]] = value
+		}
 	}
-	return c.client.MGet(keys...).Result()
+	return results, nil
 }
 
 func (c *standardRedisClient) Set(key string, value interface{}, expiration time.Duration) error {
-	if c.ring != nil {
-		return c.ring.Set(key, value, expiration).Err()
-	}
 	return c.client.Set(key, value, expiration).Err()
 }
 
 func (c *standardRedisClient) MSet(pairs ...interface{}) error {
-	if c.ring != nil {
-		return c.ring.MSet(pairs...).Err()
+	if c.cluster == nil {
+		return c.client.MSet(pairs...).Err()
 	}
-	return c.client.MSet(pairs...).Err()
+	_, err := c.cluster.Pipelined(func(pipeline redis.Pipeliner) error {
+		for _, slotPairs := range groupRedisPairsBySlot(pairs) {
+			pipeline.MSet(slotPairs...)
+		}
+		return nil
+	})
+	return err
+}
+
+func (c *standardRedisClient) SetNX(key string, value interface{}, expiration time.Duration) (bool, error) {
+	return c.client.SetNX(key, value, expiration).Result()
+}
+
+func (c *standardRedisClient) MSetEx(expiration time.Duration, pairs ...interface{}) error {
+	groups := [][]interface{}{pairs}
+	if c.cluster != nil {
+		groups = groupRedisPairsBySlot(pairs)
+	}
+	_, err := c.client.Pipelined(func(pipeline redis.Pipeliner) error {
+		for _, slotPairs := range groups {
+			for i := 0; i < len(slotPairs); i += 2 {
+				pipeline.Set(slotPairs[i].(string), slotPairs[i+1], expiration)
+			}
+		}
+		return nil
+	})
+	return err
 }
 
 func (c *standardRedisClient) Del(keys ...string) error {
-	if c.ring != nil {
-		return c.ring.Del(keys...).Err()
+	if c.cluster == nil {
+		return c.client.Del(keys...).Err()
+	}
+	slots, _ := groupRedisKeysBySlot(keys)
+	_, err := c.cluster.Pipelined(func(pipeline redis.Pipeliner) error {
+		for _, slotKeys := range slots {
+			pipeline.Del(slotKeys...)
+		}
+		return nil
+	})
+	return err
+}
+
+func (c *standardRedisClient) Eval(script string, keys []string, args ...interface{}) (interface{}, error) {
+	if c.cluster != nil {
+		err := checkRedisClusterSlot(keys)
+		if err != nil {
+			return nil, err
+		}
 	}
-	return c.client.Del(keys...).Err()
+	return c.client.Eval(script, keys, args...).Result()
 }
 
 func (c *standardRedisClient) FlushDB() error {
-	if c.ring != nil {
-		return c.ring.FlushDB().Err()
+	if c.cluster == nil {
+		return c.client.FlushDB().Err()
 	}
-	return c.client.FlushDB().Err()
+	return c.cluster.ForEachMaster(func(client *redis.Client) error {
+		return client.FlushDB().Err()
+	})
+}
+
+func (c *standardRedisClient) Incr(key string) (int64, error) {
+	return c.client.Incr(key).Result()
+}
+
+func (c *standardRedisClient) IncrBy(key string, value int64) (int64, error) {
+	return c.client.IncrBy(key, value).Result()
+}
+
+func (c *standardRedisClient) HIncrBy(key, field string, incr int64) (int64, error) {
+	return c.client.HIncrBy(key, field, incr).Result()
+}
+
+func (c *standardRedisClient) HDel(key string, fields ...string) (int64, error) {
+	return c.client.HDel(key, fields...).Result()
+}
+
+func (c *standardRedisClient) Expire(key string, expiration time.Duration) (bool, error) {
+	return c.client.Expire(key, expiration).Result()
+}
+
+func (c *standardRedisClient) TTL(key string) (time.Duration, error) {
+	return c.client.TTL(key).Result()
+}
+
+func (c *standardRedisClient) ZRangeByScore(key string, opt *redis.ZRangeBy) ([]string, error) {
+	return c.client.ZRangeByScore(key, opt).Result()
+}
+
+func (c *standardRedisClient) ZRem(key string, members ...interface{}) (int64, error) {
+	return c.client.ZRem(key, members...).Result()
+}
+
+func (c *standardRedisClient) ZRevRange(key string, start, stop int64) ([]string, error) {
+	return c.client.ZRevRange(key, start, stop).Result()
+}
+
+func (c *standardRedisClient) SMembers(key string) ([]string, error) {
+	return c.client.SMembers(key).Result()
+}
+
+func (c *standardRedisClient) SIsMember(key string, member interface{}) (bool, error) {
+	return c.client.SIsMember(key, member).Result()
+}
+
+func (c *standardRedisClient) Exists(keys ...string) (int64, error) {
+	if c.cluster == nil {
+		return c.client.Exists(keys...).Result()
+	}
+	slots, _ := groupRedisKeysBySlot(keys)
+	commands := make([]*redis.IntCmd, len(slots))
+	_, err := c.cluster.Pipelined(func(pipeline redis.Pipeliner) error {
+		for i, slotKeys := range slots {
+			commands[i] = pipeline.Exists(slotKeys...)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	total := int64(0)
+	for _, command := range commands {
+		total += command.Val()
+	}
+	return total, nil
+}
+
+func (c *standardRedisClient) Scan(cursor uint64, match string, count int64) ([]string, uint64, error) {
+	if c.cluster == nil {
+		return c.client.Scan(cursor, match, count).Result()
+	}
+	masters := make(map[string]*redis.Client)
+	mutex := sync.Mutex{}
+	err := c.cluster.ForEachMaster(func(client *redis.Client) error {
+		mutex.Lock()
+		masters[client.Options().Addr] = client
+		mutex.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	addresses := make([]string, 0, len(masters))
+	for address := range masters {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+	node, nodeCursor := splitRedisClusterCursor(cursor)
+	if node >= len(addresses) {
+		return []string{}, 0, nil
+	}
+	keys, nodeCursor, err := masters[addresses[node]].Scan(nodeCursor, match, count).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+	if nodeCursor == 0 {
+		node++
+		if node == len(addresses) {
+			return keys, 0, nil
+		}
+	}
+	return keys, joinRedisClusterCursor(node, nodeCursor), nil
+}
+
+func (c *standardRedisClient) XAdd(a *redis.XAddArgs) (string, error) {
+	return c.client.XAdd(a).Result()
+}
+
+func (c *standardRedisClient) XLen(stream string) (int64, error) {
+	return c.client.XLen(stream).Result()
+}
+
+func (c *standardRedisClient) XGroupCreateMkStream(stream, group, start string) (string, error) {
+	return c.client.XGroupCreateMkStream(stream, group, start).Result()
+}
+
+func (c *standardRedisClient) XGroupDelConsumer(stream, group, consumer string) (int64, error) {
+	return c.client.XGroupDelConsumer(stream, group, consumer).Result()
+}
+
+func (c *standardRedisClient) XReadGroup(a *redis.XReadGroupArgs) ([]redis.XStream, error) {
+	return c.client.XReadGroup(a).Result()
+}
+
+func (c *standardRedisClient) XAck(stream, group string, ids ...string) (int64, error) {
+	return c.client.XAck(stream, group, ids...).Result()
+}
+
+func (c *standardRedisClient) XPendingExt(a *redis.XPendingExtArgs) ([]redis.XPendingExt, error) {
+	return c.client.XPendingExt(a).Result()
+}
+
+func (c *standardRedisClient) XClaim(a *redis.XClaimArgs) ([]redis.XMessage, error) {
+	return c.client.XClaim(a).Result()
+}
+
+func (c *standardRedisClient) Pipelined(fn func(redis.Pipeliner) error) ([]redis.Cmder, error) {
+	return c.client.Pipelined(fn)
+}
+
+func (c *standardRedisClient) TxPipelined(fn func(redis.Pipeliner) error) ([]redis.Cmder, error) {
+	return c.client.TxPipelined(fn)
 }
 
 type RedisCache struct {
 	engine *Engine
 	code   string
 	client redisClient
+	group  *singleFlight
 }
 
 type GetSetProvider func() interface{}
@@ -231,9 +404,19 @@ type GetSetProvider func() interface{}
 func (r *RedisCache) GetSet(key string, ttlSeconds int, provider GetSetProvider) interface{} {
 	val, has := r.Get(key)
 	if !has {
-		userVal := provider()
-		encoded, _ := jsoniter.ConfigFastest.Marshal(userVal)
-		r.Set(key, string(encoded), ttlSeconds)
+		userVal, _ := r.group.do(key, func() interface{} {
+			val, has, unlock := r.stampedeLock(key)
+			if has {
+				var data interface{}
+				_ = jsoniter.ConfigFastest.Unmarshal([]byte(val), &data)
+				return data
+			}
+			defer unlock()
+			userVal := provider()
+			encoded, _ := jsoniter.ConfigFastest.Marshal(userVal)
+			r.Set(key, string(encoded), ttlSeconds)
+			return userVal
+		})
 		return userVal
 	}
 	var data interface{}
@@ -241,6 +424,35 @@ func (r *RedisCache) GetSet(key string, ttlSeconds int, provider GetSetProvider)
 	return data
 }
 
+func (r *RedisCache) stampedeLock(key string) (value string, has bool, unlock func()) {
+	ttl := r.engine.registry.stampedeLockTTL
+	if ttl == 0 {
+		return "", false, func() {}
+	}
+	lockKey := key + ":lock"
+	deadline := time.Now().Add(ttl)
+	for {
+		if r.setNX(lockKey, "1", ttl) {
+			value, has = r.Get(key)
+			if has {
+				r.Del(lockKey)
+				return value, true, nil
+			}
+			return "", false, func() {
+				r.Del(lockKey)
+			}
+		}
+		time.Sleep(stampedeLockRetryInterval)
+		value, has = r.Get(key)
+		if has {
+			return value, true, nil
+		}
+		if time.Now().After(deadline) {
+			return "", false, func() {}
+		}
+	}
+}
+
 func (r *RedisCache) Get(key string) (value string, has bool) {
 	start := time.Now()
 	val, err := r.client.Get(key)
@@ -634,6 +846,29 @@ func (r *RedisCache) Set(key string, value interface{}, ttlSeconds int) {
 	}
 }
 
+func (r *RedisCache) SetNX(key string, value interface{}, ttlSeconds int) bool {
+	return r.setNX(key, value, time.Duration(ttlSeconds)*time.Second)
+}
+
+func (r *RedisCache) setNX(key string, value interface{}, ttl time.Duration) bool {
+	start := time.Now()
+	isSet, err := r.client.SetNX(key, value, ttl)
+	if r.engine.queryLoggers[QueryLoggerSourceRedis] != nil {
+		misses := 0
+		if !isSet {
+			misses = 1
+		}
+		r.fillLogFields("[ORM][REDIS][SETNX]", start, "setnx", misses, 1,
+			map[string]interface{}{"Key": key, "value": value, "ttl": ttl.Seconds()}, err)
+	}
+	r.engine.dataDog.incrementCounter(counterRedisAll, 1)
+	r.engine.dataDog.incrementCounter(counterRedisKeysSet, 1)
+	if err != nil {
+		panic(err)
+	}
+	return isSet
+}
+
 func (r *RedisCache) MSet(pairs ...interface{}) {
 	start := time.Now()
 	err := r.client.MSet(pairs...)
@@ -648,6 +883,20 @@ func (r *RedisCache) MSet(pairs ...interface{}) {
 	}
 }
 
+func (r *RedisCache) MSetEx(ttlSeconds int, pairs ...interface{}) {
+	start := time.Now()
+	err := r.client.MSetEx(time.Duration(ttlSeconds)*time.Second, pairs...)
+	if r.engine.queryLoggers[QueryLoggerSourceRedis] != nil {
+		r.fillLogFields("[ORM][REDIS][MSETEX]", start, "msetex", -1, len(pairs),
+			map[string]interface{}{"Pairs": pairs, "ttl": ttlSeconds}, err)
+	}
+	r.engine.dataDog.incrementCounter(counterRedisAll, 1)
+	r.engine.dataDog.incrementCounter(counterRedisKeysSet, uint(len(pairs)))
+	if err != nil {
+		panic(err)
+	}
+}
+
 func (r *RedisCache) Del(keys ...string) {
 	start := time.Now()
 	err := r.client.Del(keys...)
@@ -662,6 +911,357 @@ func (r *RedisCache) Del(keys ...string) {
 	}
 }
 
+// Eval panics in redis cluster when keys are not in the same slot, use hash tags like {user1}:name
+func (r *RedisCache) Eval(script string, keys []string, args ...interface{}) interface{} {
+	start := time.Now()
+	val, err := r.client.Eval(script, keys, args...)
+	if r.engine.queryLoggers[QueryLoggerSourceRedis] != nil {
+		r.fillLogFields("[ORM][REDIS][EVAL]", start, "eval", -1, len(keys),
+			map[string]interface{}{"Keys": keys, "args": args}, err)
+	}
+	r.engine.dataDog.incrementCounter(counterRedisAll, 1)
+	r.engine.dataDog.incrementCounter(counterRedisKeysSet, uint(len(keys)))
+	if err != nil && err != redis.Nil {
+		panic(err)
+	}
+	return val
+}
+
+func (r *RedisCache) Incr(key string) int64 {
+	start := time.Now()
+	val, err := r.client.Incr(key)
+	if r.engine.queryLoggers[QueryLoggerSourceRedis] != nil {
+		r.fillLogFields("[ORM][REDIS][INCR]", start, "incr", -1, 1,
+			map[string]interface{}{"Key": key}, err)
+	}
+	r.engine.dataDog.incrementCounter(counterRedisAll, 1)
+	r.engine.dataDog.incrementCounter(counterRedisKeysSet, 1)
+	if err != nil {
+		panic(err)
+	}
+	return val
+}
+
+func (r *RedisCache) IncrBy(key string, increment int64) int64 {
+	start := time.Now()
+	val, err := r.client.IncrBy(key, increment)
+	if r.engine.queryLoggers[QueryLoggerSourceRedis] != nil {
+		r.fillLogFields("[ORM][REDIS][INCRBY]", start, "incrby", -1, 1,
+			map[string]interface{}{"Key": key, "increment": increment}, err)
+	}
+	r.engine.dataDog.incrementCounter(counterRedisAll, 1)
+	r.engine.dataDog.incrementCounter(counterRedisKeysSet, 1)
+	if err != nil {
+		panic(err)
+	}
+	return val
+}
+
+func (r *RedisCache) HIncrBy(key, field string, increment int64) int64 {
+	start := time.Now()
+	val, err := r.client.HIncrBy(key, field, increment)
+	if r.engine.queryLoggers[QueryLoggerSourceRedis] != nil {
+		r.fillLogFields("[ORM][REDIS][HINCRBY]", start, "hincrby", -1, 1,
+			map[string]interface{}{"Key": key, "field": field, "increment": increment}, err)
+	}
+	r.engine.dataDog.incrementCounter(counterRedisAll, 1)
+	r.engine.dataDog.incrementCounter(counterRedisKeysSet, 1)
+	if err != nil {
+		panic(err)
+	}
+	return val
+}
+
+func (r *RedisCache) HDel(key string, fields ...string) int64 {
+	start := time.Now()
+	val, err := r.client.HDel(key, fields...)
+	if r.engine.queryLoggers[QueryLoggerSourceRedis] != nil {
+		r.fillLogFields("[ORM][REDIS][HDEL]", start, "hdel", -1, len(fields),
+			map[string]interface{}{"Key": key, "fields": fields}, err)
+	}
+	r.engine.dataDog.incrementCounter(counterRedisAll, 1)
+	r.engine.dataDog.incrementCounter(counterRedisKeysSet, uint(len(fields)))
+	if err != nil {
+		panic(err)
+	}
+	return val
+}
+
+func (r *RedisCache) Expire(key string, ttlSeconds int) bool {
+	start := time.Now()
+	val, err := r.client.Expire(key, time.Duration(ttlSeconds)*time.Second)
+	if r.engine.queryLoggers[QueryLoggerSourceRedis] != nil {
+		misses := 0
+		if !val {
+			misses = 1
+		}
+		r.fillLogFields("[ORM][REDIS][EXPIRE]", start, "expire", misses, 1,
+			map[string]interface{}{"Key": key, "ttl": ttlSeconds}, err)
+	}
+	r.engine.dataDog.incrementCounter(counterRedisAll, 1)
+	r.engine.dataDog.incrementCounter(counterRedisKeysSet, 1)
+	if err != nil {
+		panic(err)
+	}
+	return val
+}
+
+func (r *RedisCache) TTL(key string) time.Duration {
+	start := time.Now()
+	val, err := r.client.TTL(key)
+	if r.engine.queryLoggers[QueryLoggerSourceRedis] != nil {
+		r.fillLogFields("[ORM][REDIS][TTL]", start, "ttl", -1, 1,
+			map[string]interface{}{"Key": key}, err)
+	}
+	r.engine.dataDog.incrementCounter(counterRedisAll, 1)
+	r.engine.dataDog.incrementCounter(counterRedisKeysGet, 1)
+	if err != nil {
+		panic(err)
+	}
+	return val
+}
+
+func (r *RedisCache) Exists(keys ...string) int64 {
+	start := time.Now()
+	val, err := r.client.Exists(keys...)
+	if r.engine.queryLoggers[QueryLoggerSourceRedis] != nil {
+		r.fillLogFields("[ORM][REDIS][EXISTS]", start, "exists", len(keys)-int(val), len(keys),
+			map[string]interface{}{"Keys": keys}, err)
+	}
+	r.engine.dataDog.incrementCounter(counterRedisAll, 1)
+	r.engine.dataDog.incrementCounter(counterRedisKeysGet, uint(len(keys)))
+	if err != nil {
+		panic(err)
+	}
+	return val
+}
+
+func (r *RedisCache) ZRangeByScore(key string, opt *redis.ZRangeBy) []string {
+	start := time.Now()
+	val, err := r.client.ZRangeByScore(key, opt)
+	if r.engine.queryLoggers[QueryLoggerSourceRedis] != nil {
+		r.fillLogFields("[ORM][REDIS][ZRANGEBYSCORE]", start, "zrangebyscore", -1, 1,
+			map[string]interface{}{"Key": key, "min": opt.Min, "max": opt.Max, "offset": opt.Offset, "count": opt.Count}, err)
+	}
+	r.engine.dataDog.incrementCounter(counterRedisAll, 1)
+	r.engine.dataDog.incrementCounter(counterRedisKeysGet, 1)
+	if err != nil {
+		panic(err)
+	}
+	return val
+}
+
+func (r *RedisCache) ZRem(key string, members ...interface{}) int64 {
+	start := time.Now()
+	val, err := r.client.ZRem(key, members...)
+	if r.engine.queryLoggers[QueryLoggerSourceRedis] != nil {
+		r.fillLogFields("[ORM][REDIS][ZREM]", start, "zrem", -1, len(members),
+			map[string]interface{}{"Key": key, "members": members}, err)
+	}
+	r.engine.dataDog.incrementCounter(counterRedisAll, 1)
+	r.engine.dataDog.incrementCounter(counterRedisKeysSet, uint(len(members)))
+	if err != nil {
+		panic(err)
+	}
+	return val
+}
+
+func (r *RedisCache) ZRevRange(key string, start, stop int64) []string {
+	s := time.Now()
+	val, err := r.client.ZRevRange(key, start, stop)
+	if r.engine.queryLoggers[QueryLoggerSourceRedis] != nil {
+		r.fillLogFields("[ORM][REDIS][ZREVRANGE]", s, "zrevrange", -1, 1,
+			map[string]interface{}{"Key": key, "start": start, "stop": stop}, err)
+	}
+	r.engine.dataDog.incrementCounter(counterRedisAll, 1)
+	r.engine.dataDog.incrementCounter(counterRedisKeysGet, 1)
+	if err != nil {
+		panic(err)
+	}
+	return val
+}
+
+func (r *RedisCache) SMembers(key string) []string {
+	start := time.Now()
+	val, err := r.client.SMembers(key)
+	if r.engine.queryLoggers[QueryLoggerSourceRedis] != nil {
+		r.fillLogFields("[ORM][REDIS][SMEMBERS]", start, "smembers", -1, 1,
+			map[string]interface{}{"Key": key}, err)
+	}
+	r.engine.dataDog.incrementCounter(counterRedisAll, 1)
+	r.engine.dataDog.incrementCounter(counterRedisKeysGet, 1)
+	if err != nil {
+		panic(err)
+	}
+	return val
+}
+
+func (r *RedisCache) SIsMember(key string, member interface{}) bool {
+	start := time.Now()
+	val, err := r.client.SIsMember(key, member)
+	if r.engine.queryLoggers[QueryLoggerSourceRedis] != nil {
+		misses := 0
+		if !val {
+			misses = 1
+		}
+		r.fillLogFields("[ORM][REDIS][SISMEMBER]", start, "sismember", misses, 1,
+			map[string]interface{}{"Key": key, "member": member}, err)
+	}
+	r.engine.dataDog.incrementCounter(counterRedisAll, 1)
+	r.engine.dataDog.incrementCounter(counterRedisKeysGet, 1)
+	if err != nil {
+		panic(err)
+	}
+	return val
+}
+
+// Scan in redis cluster iterates master nodes one by one, returned cursor holds node number and cursor of this node
+func (r *RedisCache) Scan(cursor uint64, match string, count int64) (keys []string, nextCursor uint64) {
+	start := time.Now()
+	keys, nextCursor, err := r.client.Scan(cursor, match, count)
+	if r.engine.queryLoggers[QueryLoggerSourceRedis] != nil {
+		r.fillLogFields("[ORM][REDIS][SCAN]", start, "scan", -1, len(keys),
+			map[string]interface{}{"cursor": cursor, "match": match, "count": count}, err)
+	}
+	r.engine.dataDog.incrementCounter(counterRedisAll, 1)
+	r.engine.dataDog.incrementCounter(counterRedisKeysGet, uint(len(keys)))
+	if err != nil {
+		panic(err)
+	}
+	return keys, nextCursor
+}
+
+func (r *RedisCache) XAdd(a *redis.XAddArgs) string {
+	start := time.Now()
+	id, err := r.client.XAdd(a)
+	if r.engine.queryLoggers[QueryLoggerSourceRedis] != nil {
+		r.fillLogFields("[ORM][REDIS][XADD]", start, "xadd", -1, 1,
+			map[string]interface{}{"Key": a.Stream, "values": a.Values}, err)
+	}
+	r.engine.dataDog.incrementCounter(counterRedisAll, 1)
+	r.engine.dataDog.incrementCounter(counterRedisKeysSet, 1)
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+func (r *RedisCache) XLen(stream string) int64 {
+	start := time.Now()
+	val, err := r.client.XLen(stream)
+	if r.engine.queryLoggers[QueryLoggerSourceRedis] != nil {
+		r.fillLogFields("[ORM][REDIS][XLEN]", start, "xlen", -1, 1,
+			map[string]interface{}{"Key": stream}, err)
+	}
+	r.engine.dataDog.incrementCounter(counterRedisAll, 1)
+	r.engine.dataDog.incrementCounter(counterRedisKeysGet, 1)
+	if err != nil {
+		panic(err)
+	}
+	return val
+}
+
+func (r *RedisCache) XGroupCreateMkStream(stream, group, start string) (exists bool) {
+	s := time.Now()
+	_, err := r.client.XGroupCreateMkStream(stream, group, start)
+	if err != nil && strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		exists = true
+		err = nil
+	}
+	if r.engine.queryLoggers[QueryLoggerSourceRedis] != nil {
+		r.fillLogFields("[ORM][REDIS][XGROUPCREATEMKSTREAM]", s, "xgroupcreatemkstream", -1, 1,
+			map[string]interface{}{"Key": stream, "group": group, "start": start, "exists": exists}, err)
+	}
+	r.engine.dataDog.incrementCounter(counterRedisAll, 1)
+	r.engine.dataDog.incrementCounter(counterRedisKeysSet, 1)
+	if err != nil {
+		panic(err)
+	}
+	return exists
+}
+
+func (r *RedisCache) XGroupDelConsumer(stream, group, consumer string) (pending int64) {
+	start := time.Now()
+	pending, err := r.client.XGroupDelConsumer(stream, group, consumer)
+	if r.engine.queryLoggers[QueryLoggerSourceRedis] != nil {
+		r.fillLogFields("[ORM][REDIS][XGROUPDELCONSUMER]", start, "xgroupdelconsumer", -1, 1,
+			map[string]interface{}{"Key": stream, "group": group, "consumer": consumer, "pending": pending}, err)
+	}
+	r.engine.dataDog.incrementCounter(counterRedisAll, 1)
+	r.engine.dataDog.incrementCounter(counterRedisKeysSet, 1)
+	if err != nil {
+		panic(err)
+	}
+	return pending
+}
+
+func (r *RedisCache) XReadGroup(a *redis.XReadGroupArgs) []redis.XStream {
+	start := time.Now()
+	val, err := r.client.XReadGroup(a)
+	if err == redis.Nil {
+		err = nil
+	}
+	if r.engine.queryLoggers[QueryLoggerSourceRedis] != nil {
+		messages := 0
+		for _, stream := range val {
+			messages += len(stream.Messages)
+		}
+		r.fillLogFields("[ORM][REDIS][XREADGROUP]", start, "xreadgroup", -1, messages,
+			map[string]interface{}{"Streams": a.Streams, "group": a.Group, "consumer": a.Consumer, "count": a.Count}, err)
+	}
+	r.engine.dataDog.incrementCounter(counterRedisAll, 1)
+	r.engine.dataDog.incrementCounter(counterRedisKeysGet, uint(len(a.Streams)/2))
+	if err != nil {
+		panic(err)
+	}
+	return val
+}
+
+func (r *RedisCache) XAck(stream, group string, ids ...string) int64 {
+	start := time.Now()
+	val, err := r.client.XAck(stream, group, ids...)
+	if r.engine.queryLoggers[QueryLoggerSourceRedis] != nil {
+		r.fillLogFields("[ORM][REDIS][XACK]", start, "xack", -1, len(ids),
+			map[string]interface{}{"Key": stream, "group": group, "ids": ids}, err)
+	}
+	r.engine.dataDog.incrementCounter(counterRedisAll, 1)
+	r.engine.dataDog.incrementCounter(counterRedisKeysSet, uint(len(ids)))
+	if err != nil {
+		panic(err)
+	}
+	return val
+}
+
+func (r *RedisCache) XPendingExt(a *redis.XPendingExtArgs) []redis.XPendingExt {
+	start := time.Now()
+	val, err := r.client.XPendingExt(a)
+	if r.engine.queryLoggers[QueryLoggerSourceRedis] != nil {
+		r.fillLogFields("[ORM][REDIS][XPENDINGEXT]", start, "xpendingext", -1, len(val),
+			map[string]interface{}{"Key": a.Stream, "group": a.Group, "start": a.Start, "end": a.End, "count": a.Count}, err)
+	}
+	r.engine.dataDog.incrementCounter(counterRedisAll, 1)
+	r.engine.dataDog.incrementCounter(counterRedisKeysGet, 1)
+	if err != nil {
+		panic(err)
+	}
+	return val
+}
+
+func (r *RedisCache) XClaim(a *redis.XClaimArgs) []redis.XMessage {
+	start := time.Now()
+	val, err := r.client.XClaim(a)
+	if r.engine.queryLoggers[QueryLoggerSourceRedis] != nil {
+		r.fillLogFields("[ORM][REDIS][XCLAIM]", start, "xclaim", len(a.Messages)-len(val), len(a.Messages),
+			map[string]interface{}{"Key": a.Stream, "group": a.Group, "consumer": a.Consumer, "ids": a.Messages}, err)
+	}
+	r.engine.dataDog.incrementCounter(counterRedisAll, 1)
+	r.engine.dataDog.incrementCounter(counterRedisKeysSet, uint(len(a.Messages)))
+	if err != nil {
+		panic(err)
+	}
+	return val
+}
+
 func (r *RedisCache) FlushDB() {
 	start := time.Now()
 	err := r.client.FlushDB()
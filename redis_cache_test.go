@@ -1,7 +1,11 @@
 package orm
 
 import (
+	"fmt"
 	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v7"
 
 	log2 "github.com/apex/log"
 
@@ -46,12 +50,25 @@ func TestHash(t *testing.T) {
 	engine.AddQueryLogger(testLogger, log2.InfoLevel, QueryLoggerSourceRedis)
 	mockClient := &mockRedisClient{client: r.client}
 	r.client = mockClient
+
+	assert.Equal(t, int64(2), r.HIncrBy("hash", "counter", 2))
+	assert.Equal(t, int64(5), r.HIncrBy("hash", "counter", 3))
+	r.HSet("hash", "name", "a")
+	assert.Equal(t, int64(1), r.HDel("hash", "name", "missing"))
+	assert.Equal(t, map[string]string{"counter": "5"}, r.HGetAll("hash"))
+	assert.Equal(t, "[ORM][REDIS][HINCRBY]", testLogger.Entries[0].Message)
+	assert.Equal(t, "[ORM][REDIS][HDEL]", testLogger.Entries[3].Message)
 }
 
 func TestSet(t *testing.T) {
 	r, _ := prepareRedis(t)
 	mockClient := &mockRedisClient{client: r.client}
 	r.client = mockClient
+
+	r.SAdd("set", "a", "b")
+	assert.ElementsMatch(t, []string{"a", "b"}, r.SMembers("set"))
+	assert.True(t, r.SIsMember("set", "a"))
+	assert.False(t, r.SIsMember("set", "c"))
 }
 
 func TestSortedSet(t *testing.T) {
@@ -60,6 +77,60 @@ func TestSortedSet(t *testing.T) {
 	engine.AddQueryLogger(testLogger, log2.InfoLevel, QueryLoggerSourceRedis)
 	mockClient := &mockRedisClient{client: r.client}
 	r.client = mockClient
+
+	r.ZAdd("board", &redis.Z{Score: 10, Member: "a"}, &redis.Z{Score: 20, Member: "b"}, &redis.Z{Score: 30, Member: "c"})
+	assert.Equal(t, []string{"c", "b", "a"}, r.ZRevRange("board", 0, -1))
+	assert.Equal(t, []string{"a", "b"}, r.ZRangeByScore("board", &redis.ZRangeBy{Min: "10", Max: "25"}))
+	assert.Equal(t, []string{"b"}, r.ZRangeByScore("board", &redis.ZRangeBy{Min: "-inf", Max: "+inf", Offset: 1, Count: 1}))
+	assert.Equal(t, int64(1), r.ZRem("board", "b", "missing"))
+	assert.Equal(t, int64(2), r.ZCard("board"))
+	assert.Equal(t, "[ORM][REDIS][ZREVRANGE]", testLogger.Entries[1].Message)
+}
+
+func TestCounters(t *testing.T) {
+	r, engine := prepareRedis(t)
+	testLogger := memory.New()
+	engine.AddQueryLogger(testLogger, log2.InfoLevel, QueryLoggerSourceRedis)
+
+	assert.Equal(t, int64(1), r.Incr("counter"))
+	assert.Equal(t, int64(11), r.IncrBy("counter", 10))
+	assert.Equal(t, int64(1), r.Exists("counter", "missing"))
+	assert.Equal(t, 1, testLogger.Entries[2].Fields["misses"])
+	assert.Equal(t, time.Duration(-1), r.TTL("counter"))
+	assert.True(t, r.Expire("counter", 10))
+	assert.False(t, r.Expire("missing", 10))
+	ttl := r.TTL("counter")
+	assert.True(t, ttl > 0 && ttl <= 10*time.Second)
+
+	mockClient := &mockRedisClient{client: r.client}
+	mockClient.IncrMock = func(key string) (int64, error) {
+		return 0, fmt.Errorf("redis error")
+	}
+	r.client = mockClient
+	assert.PanicsWithError(t, "redis error", func() {
+		r.Incr("counter")
+	})
+}
+
+func TestScan(t *testing.T) {
+	r, _ := prepareRedis(t)
+	for i := 0; i < 30; i++ {
+		r.Set(fmt.Sprintf("scan:%d", i), "1", 0)
+	}
+	r.Set("other", "1", 0)
+	found := make(map[string]bool)
+	cursor := uint64(0)
+	for {
+		var keys []string
+		keys, cursor = r.Scan(cursor, "scan:*", 10)
+		for _, key := range keys {
+			found[key] = true
+		}
+		if cursor == 0 {
+			break
+		}
+	}
+	assert.Len(t, found, 30)
 }
 
 func prepareRedis(t *testing.T) (*RedisCache, *Engine) {
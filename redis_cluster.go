@@ -0,0 +1,93 @@
+package orm
+
+import (
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+const redisClusterSlots = 16384
+
+var redisCRC16Table [256]uint16
+
+func init() {
+	for i := 0; i < 256; i++ {
+		crc := uint16(i) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+		redisCRC16Table[i] = crc
+	}
+}
+
+func redisClusterSlot(key string) int {
+	start := strings.IndexByte(key, '{')
+	if start >= 0 {
+		end := strings.IndexByte(key[start+1:], '}')
+		if end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+	crc := uint16(0)
+	for i := 0; i < len(key); i++ {
+		crc = crc<<8 ^ redisCRC16Table[byte(crc>>8)^key[i]]
+	}
+	return int(crc) % redisClusterSlots
+}
+
+func groupRedisKeysBySlot(keys []string) (slots [][]string, positions [][]int) {
+	indexes := make(map[int]int)
+	for i, key := range keys {
+		slot := redisClusterSlot(key)
+		index, has := indexes[slot]
+		if !has {
+			index = len(slots)
+			indexes[slot] = index
+			slots = append(slots, nil)
+			positions = append(positions, nil)
+		}
+		slots[index] = append(slots[index], key)
+		positions[index] = append(positions[index], i)
+	}
+	return slots, positions
+}
+
+func groupRedisPairsBySlot(pairs []interface{}) [][]interface{} {
+	indexes := make(map[int]int)
+	slots := make([][]interface{}, 0)
+	for i := 0; i < len(pairs); i += 2 {
+		slot := redisClusterSlot(pairs[i].(string))
+		index, has := indexes[slot]
+		if !has {
+			index = len(slots)
+			indexes[slot] = index
+			slots = append(slots, nil)
+		}
+		slots[index] = append(slots[index], pairs[i], pairs[i+1])
+	}
+	return slots
+}
+
+func checkRedisClusterSlot(keys []string) error {
+	for i := 1; i < len(keys); i++ {
+		if redisClusterSlot(keys[i]) != redisClusterSlot(keys[0]) {
+			return errors.NotValidf("keys %v in different cluster slots", keys)
+		}
+	}
+	return nil
+}
+
+// redis cluster cursor keeps master node number in highest bits and cursor of this node in lower bits
+const redisClusterCursorNodeShift = 54
+
+func splitRedisClusterCursor(cursor uint64) (node int, nodeCursor uint64) {
+	return int(cursor >> redisClusterCursorNodeShift), cursor & (1<<redisClusterCursorNodeShift - 1)
+}
+
+func joinRedisClusterCursor(node int, nodeCursor uint64) uint64 {
+	return uint64(node)<<redisClusterCursorNodeShift | nodeCursor
+}
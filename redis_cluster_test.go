@@ -0,0 +1,39 @@
+package orm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedisClusterSlot(t *testing.T) {
+	assert.Equal(t, 12739, redisClusterSlot("123456789"))
+	assert.Equal(t, 12182, redisClusterSlot("foo"))
+	assert.Equal(t, redisClusterSlot("user1000"), redisClusterSlot("{user1000}.following"))
+	assert.Equal(t, redisClusterSlot("{user1000}.following"), redisClusterSlot("{user1000}.followers"))
+	assert.Equal(t, redisClusterSlot("foo{}{bar}"), redisClusterSlot("foo{}{bar}"))
+	assert.NotEqual(t, redisClusterSlot("bar"), redisClusterSlot("foo{}{bar}"))
+
+	slots, positions := groupRedisKeysBySlot([]string{"{a}1", "{b}1", "{a}2", "{b}2", "{c}1"})
+	assert.Equal(t, [][]string{{"{a}1", "{a}2"}, {"{b}1", "{b}2"}, {"{c}1"}}, slots)
+	assert.Equal(t, [][]int{{0, 2}, {1, 3}, {4}}, positions)
+
+	pairs := groupRedisPairsBySlot([]interface{}{"{a}1", 1, "{b}1", 2, "{a}2", 3})
+	assert.Equal(t, [][]interface{}{{"{a}1", 1, "{a}2", 3}, {"{b}1", 2}}, pairs)
+
+	node, nodeCursor := splitRedisClusterCursor(joinRedisClusterCursor(3, 12345))
+	assert.Equal(t, 3, node)
+	assert.Equal(t, uint64(12345), nodeCursor)
+	node, nodeCursor = splitRedisClusterCursor(17)
+	assert.Equal(t, 0, node)
+	assert.Equal(t, uint64(17), nodeCursor)
+
+	assert.Nil(t, checkRedisClusterSlot([]string{"{a}1", "{a}2"}))
+	assert.EqualError(t, checkRedisClusterSlot([]string{"{a}1", "{b}1"}), "keys [{a}1 {b}1] in different cluster slots not valid")
+
+	pipeline := &RedisPipeline{cluster: true, transaction: true, slot: -1}
+	pipeline.checkSlot("{a}1", "{a}2")
+	assert.PanicsWithError(t, "transaction with keys in different cluster slots not valid", func() {
+		pipeline.checkSlot("{b}1")
+	})
+}
@@ -0,0 +1,349 @@
+package orm
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+
+	"github.com/go-redis/redis/v7"
+)
+
+const counterRedisPipeline = "redis.pipeline"
+
+type RedisPipeline struct {
+	pipeline    redis.Pipeliner
+	cluster     bool
+	transaction bool
+	slot        int
+	commands    []string
+	keys        int
+}
+
+func (p *RedisPipeline) Get(key string) *redis.StringCmd {
+	p.add("get", 1)
+	p.checkSlot(key)
+	return p.pipeline.Get(key)
+}
+
+func (p *RedisPipeline) Set(key string, value interface{}, ttlSeconds int) *redis.StatusCmd {
+	p.add("set", 1)
+	p.checkSlot(key)
+	return p.pipeline.Set(key, value, time.Duration(ttlSeconds)*time.Second)
+}
+
+func (p *RedisPipeline) SetNX(key string, value interface{}, ttlSeconds int) *redis.BoolCmd {
+	p.add("setnx", 1)
+	p.checkSlot(key)
+	return p.pipeline.SetNX(key, value, time.Duration(ttlSeconds)*time.Second)
+}
+
+func (p *RedisPipeline) Incr(key string) *redis.IntCmd {
+	p.add("incr", 1)
+	p.checkSlot(key)
+	return p.pipeline.Incr(key)
+}
+
+func (p *RedisPipeline) IncrBy(key string, increment int64) *redis.IntCmd {
+	p.add("incrby", 1)
+	p.checkSlot(key)
+	return p.pipeline.IncrBy(key, increment)
+}
+
+func (p *RedisPipeline) HIncrBy(key, field string, increment int64) *redis.IntCmd {
+	p.add("hincrby", 1)
+	p.checkSlot(key)
+	return p.pipeline.HIncrBy(key, field, increment)
+}
+
+func (p *RedisPipeline) Expire(key string, ttlSeconds int) *redis.BoolCmd {
+	p.add("expire", 1)
+	p.checkSlot(key)
+	return p.pipeline.Expire(key, time.Duration(ttlSeconds)*time.Second)
+}
+
+func (p *RedisPipeline) HMget(key string, fields ...string) *redis.SliceCmd {
+	p.add("hmget", len(fields))
+	p.checkSlot(key)
+	return p.pipeline.HMGet(key, fields...)
+}
+
+func (p *RedisPipeline) HGetAll(key string) *redis.StringStringMapCmd {
+	p.add("hgetall", 1)
+	p.checkSlot(key)
+	return p.pipeline.HGetAll(key)
+}
+
+func (p *RedisPipeline) HMset(key string, fields map[string]interface{}) *redis.BoolCmd {
+	p.add("hmset", len(fields))
+	p.checkSlot(key)
+	return p.pipeline.HMSet(key, fields)
+}
+
+func (p *RedisPipeline) HSet(key string, field string, value interface{}) *redis.IntCmd {
+	p.add("hset", 1)
+	p.checkSlot(key)
+	return p.pipeline.HSet(key, field, value)
+}
+
+func (p *RedisPipeline) LRange(key string, start, stop int64) *redis.StringSliceCmd {
+	p.add("lrange", 1)
+	p.checkSlot(key)
+	return p.pipeline.LRange(key, start, stop)
+}
+
+func (p *RedisPipeline) LPush(key string, values ...interface{}) *redis.IntCmd {
+	p.add("lpush", len(values))
+	p.checkSlot(key)
+	return p.pipeline.LPush(key, values...)
+}
+
+func (p *RedisPipeline) RPush(key string, values ...interface{}) *redis.IntCmd {
+	p.add("rpush", len(values))
+	p.checkSlot(key)
+	return p.pipeline.RPush(key, values...)
+}
+
+func (p *RedisPipeline) SAdd(key string, members ...interface{}) *redis.IntCmd {
+	p.add("sadd", len(members))
+	p.checkSlot(key)
+	return p.pipeline.SAdd(key, members...)
+}
+
+func (p *RedisPipeline) ZAdd(key string, members ...*redis.Z) *redis.IntCmd {
+	p.add("zadd", len(members))
+	p.checkSlot(key)
+	return p.pipeline.ZAdd(key, members...)
+}
+
+func (p *RedisPipeline) RPop(key string) *redis.StringCmd {
+	p.add("rpop", 1)
+	p.checkSlot(key)
+	return p.pipeline.RPop(key)
+}
+
+func (p *RedisPipeline) LSet(key string, index int64, value interface{}) *redis.StatusCmd {
+	p.add("lset", 1)
+	p.checkSlot(key)
+	return p.pipeline.LSet(key, index, value)
+}
+
+func (p *RedisPipeline) LRem(key string, count int64, value interface{}) *redis.IntCmd {
+	p.add("lrem", 1)
+	p.checkSlot(key)
+	return p.pipeline.LRem(key, count, value)
+}
+
+func (p *RedisPipeline) Ltrim(key string, start, stop int64) *redis.StatusCmd {
+	p.add("ltrim", 1)
+	p.checkSlot(key)
+	return p.pipeline.LTrim(key, start, stop)
+}
+
+func (p *RedisPipeline) LLen(key string) *redis.IntCmd {
+	p.add("llen", 1)
+	p.checkSlot(key)
+	return p.pipeline.LLen(key)
+}
+
+func (p *RedisPipeline) ZCard(key string) *redis.IntCmd {
+	p.add("zcard", 1)
+	p.checkSlot(key)
+	return p.pipeline.ZCard(key)
+}
+
+func (p *RedisPipeline) ZCount(key string, min, max string) *redis.IntCmd {
+	p.add("zcount", 1)
+	p.checkSlot(key)
+	return p.pipeline.ZCount(key, min, max)
+}
+
+func (p *RedisPipeline) ZRangeByScore(key string, opt *redis.ZRangeBy) *redis.StringSliceCmd {
+	p.add("zrangebyscore", 1)
+	p.checkSlot(key)
+	return p.pipeline.ZRangeByScore(key, opt)
+}
+
+func (p *RedisPipeline) ZRevRange(key string, start, stop int64) *redis.StringSliceCmd {
+	p.add("zrevrange", 1)
+	p.checkSlot(key)
+	return p.pipeline.ZRevRange(key, start, stop)
+}
+
+func (p *RedisPipeline) ZRem(key string, members ...interface{}) *redis.IntCmd {
+	p.add("zrem", len(members))
+	p.checkSlot(key)
+	return p.pipeline.ZRem(key, members...)
+}
+
+func (p *RedisPipeline) SCard(key string) *redis.IntCmd {
+	p.add("scard", 1)
+	p.checkSlot(key)
+	return p.pipeline.SCard(key)
+}
+
+func (p *RedisPipeline) SPop(key string) *redis.StringCmd {
+	p.add("spop", 1)
+	p.checkSlot(key)
+	return p.pipeline.SPop(key)
+}
+
+func (p *RedisPipeline) SPopN(key string, max int64) *redis.StringSliceCmd {
+	p.add("spop", 1)
+	p.checkSlot(key)
+	return p.pipeline.SPopN(key, max)
+}
+
+func (p *RedisPipeline) SMembers(key string) *redis.StringSliceCmd {
+	p.add("smembers", 1)
+	p.checkSlot(key)
+	return p.pipeline.SMembers(key)
+}
+
+func (p *RedisPipeline) SIsMember(key string, member interface{}) *redis.BoolCmd {
+	p.add("sismember", 1)
+	p.checkSlot(key)
+	return p.pipeline.SIsMember(key, member)
+}
+
+func (p *RedisPipeline) HDel(key string, fields ...string) *redis.IntCmd {
+	p.add("hdel", len(fields))
+	p.checkSlot(key)
+	return p.pipeline.HDel(key, fields...)
+}
+
+func (p *RedisPipeline) TTL(key string) *redis.DurationCmd {
+	p.add("ttl", 1)
+	p.checkSlot(key)
+	return p.pipeline.TTL(key)
+}
+
+// MGet panics in redis cluster when keys are not in the same slot
+func (p *RedisPipeline) MGet(keys ...string) *redis.SliceCmd {
+	p.add("mget", len(keys))
+	p.checkSameSlot(keys)
+	return p.pipeline.MGet(keys...)
+}
+
+// Exists panics in redis cluster when keys are not in the same slot
+func (p *RedisPipeline) Exists(keys ...string) *redis.IntCmd {
+	p.add("exists", len(keys))
+	p.checkSameSlot(keys)
+	return p.pipeline.Exists(keys...)
+}
+
+func (p *RedisPipeline) MSet(pairs ...interface{}) {
+	p.add("mset", len(pairs)/2)
+	groups := [][]interface{}{pairs}
+	if p.cluster {
+		groups = groupRedisPairsBySlot(pairs)
+	}
+	for _, slotPairs := range groups {
+		for i := 0; i < len(slotPairs); i += 2 {
+			p.checkSlot(slotPairs[i].(string))
+		}
+		p.pipeline.MSet(slotPairs...)
+	}
+}
+
+func (p *RedisPipeline) MSetEx(ttlSeconds int, pairs ...interface{}) {
+	p.add("msetex", len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		p.checkSlot(pairs[i].(string))
+		p.pipeline.Set(pairs[i].(string), pairs[i+1], time.Duration(ttlSeconds)*time.Second)
+	}
+}
+
+func (p *RedisPipeline) Del(keys ...string) {
+	p.add("del", len(keys))
+	p.checkSlot(keys...)
+	if !p.cluster {
+		p.pipeline.Del(keys...)
+		return
+	}
+	slots, _ := groupRedisKeysBySlot(keys)
+	for _, slotKeys := range slots {
+		p.pipeline.Del(slotKeys...)
+	}
+}
+
+func (p *RedisPipeline) Eval(script string, keys []string, args ...interface{}) *redis.Cmd {
+	p.add("eval", len(keys))
+	p.checkSameSlot(keys)
+	return p.pipeline.Eval(script, keys, args...)
+}
+
+func (p *RedisPipeline) add(command string, keys int) {
+	p.commands = append(p.commands, command)
+	p.keys += keys
+}
+
+func (p *RedisPipeline) checkSameSlot(keys []string) {
+	if p.cluster {
+		err := checkRedisClusterSlot(keys)
+		if err != nil {
+			panic(err)
+		}
+	}
+	p.checkSlot(keys...)
+}
+
+func (p *RedisPipeline) checkSlot(keys ...string) {
+	if !p.cluster || !p.transaction {
+		return
+	}
+	for _, key := range keys {
+		slot := redisClusterSlot(key)
+		if p.slot == -1 {
+			p.slot = slot
+		} else if p.slot != slot {
+			panic(errors.NotValidf("transaction with keys in different cluster slots"))
+		}
+	}
+}
+
+func (r *RedisCache) Pipeline(fn func(pipeline *RedisPipeline)) {
+	r.runPipeline("[ORM][REDIS][PIPELINE]", "pipeline", false, fn)
+}
+
+// TxPipeline panics in redis cluster when keys are not in the same slot, use hash tags like {user1}:name
+func (r *RedisCache) TxPipeline(fn func(pipeline *RedisPipeline)) {
+	r.runPipeline("[ORM][REDIS][TX_PIPELINE]", "tx_pipeline", true, fn)
+}
+
+func (r *RedisCache) runPipeline(message string, operation string, transaction bool, fn func(pipeline *RedisPipeline)) {
+	pipeline := &RedisPipeline{cluster: r.engine.registry.redisServers[r.code].cluster != nil, transaction: transaction, slot: -1}
+	builder := func(p redis.Pipeliner) error {
+		pipeline.pipeline = p
+		fn(pipeline)
+		return nil
+	}
+	start := time.Now()
+	var commands []redis.Cmder
+	var err error
+	if transaction {
+		commands, err = r.client.TxPipelined(builder)
+	} else {
+		commands, err = r.client.Pipelined(builder)
+	}
+	if err == redis.Nil {
+		err = nil
+		for _, command := range commands {
+			if command.Err() != nil && command.Err() != redis.Nil {
+				err = command.Err()
+				break
+			}
+		}
+	}
+	if len(pipeline.commands) == 0 && err == nil {
+		return
+	}
+	if r.engine.queryLoggers[QueryLoggerSourceRedis] != nil {
+		r.fillLogFields(message, start, operation, -1, pipeline.keys,
+			map[string]interface{}{"commands": pipeline.commands}, err)
+	}
+	r.engine.dataDog.incrementCounter(counterRedisAll, 1)
+	r.engine.dataDog.incrementCounter(counterRedisPipeline, 1)
+	if err != nil {
+		panic(err)
+	}
+}
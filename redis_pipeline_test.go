@@ -0,0 +1,79 @@
+package orm
+
+import (
+	"testing"
+
+	log2 "github.com/apex/log"
+	"github.com/apex/log/handlers/memory"
+	"github.com/go-redis/redis/v7"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedisPipeline(t *testing.T) {
+	r, engine := prepareRedis(t)
+	testLogger := memory.New()
+	engine.AddQueryLogger(testLogger, log2.InfoLevel, QueryLoggerSourceRedis)
+
+	var get, missing *redis.StringCmd
+	var hash *redis.StringStringMapCmd
+	r.Pipeline(func(pipeline *RedisPipeline) {
+		pipeline.Set("a", "1", 10)
+		pipeline.HSet("b", "field", "2")
+		pipeline.LPush("c", "x", "y")
+		get = pipeline.Get("a")
+		missing = pipeline.Get("missing")
+		hash = pipeline.HGetAll("b")
+	})
+	assert.Equal(t, "1", get.Val())
+	assert.Equal(t, redis.Nil, missing.Err())
+	assert.Equal(t, map[string]string{"field": "2"}, hash.Val())
+	assert.Len(t, testLogger.Entries, 1)
+	assert.Equal(t, "[ORM][REDIS][PIPELINE]", testLogger.Entries[0].Message)
+	assert.Equal(t, []string{"set", "hset", "lpush", "get", "get", "hgetall"}, testLogger.Entries[0].Fields["commands"])
+	assert.Equal(t, 7, testLogger.Entries[0].Fields["keys"])
+
+	var length *redis.StringSliceCmd
+	r.TxPipeline(func(pipeline *RedisPipeline) {
+		pipeline.Del("a", "b")
+		pipeline.RPush("c", "z")
+		length = pipeline.LRange("c", 0, -1)
+	})
+	assert.Equal(t, []string{"y", "x", "z"}, length.Val())
+	assert.Len(t, testLogger.Entries, 2)
+	assert.Equal(t, "[ORM][REDIS][TX_PIPELINE]", testLogger.Entries[1].Message)
+	_, has := r.Get("a")
+	assert.False(t, has)
+
+	r.Pipeline(func(pipeline *RedisPipeline) {})
+	assert.Len(t, testLogger.Entries, 2)
+
+	var values *redis.SliceCmd
+	var exists, zCount *redis.IntCmd
+	var scores *redis.StringSliceCmd
+	var ttl *redis.DurationCmd
+	r.Pipeline(func(pipeline *RedisPipeline) {
+		pipeline.MSet("d", "1", "e", "2")
+		pipeline.MSetEx(10, "f", "3")
+		pipeline.ZAdd("g", &redis.Z{Member: "a", Score: 1}, &redis.Z{Member: "b", Score: 2})
+		values = pipeline.MGet("d", "e", "f", "missing")
+		exists = pipeline.Exists("d", "e", "missing")
+		zCount = pipeline.ZCount("g", "1", "1")
+		scores = pipeline.ZRangeByScore("g", &redis.ZRangeBy{Min: "2", Max: "2"})
+		ttl = pipeline.TTL("f")
+	})
+	assert.Equal(t, []interface{}{"1", "2", "3", nil}, values.Val())
+	assert.Equal(t, int64(2), exists.Val())
+	assert.Equal(t, int64(1), zCount.Val())
+	assert.Equal(t, []string{"b"}, scores.Val())
+	assert.True(t, ttl.Val() > 0)
+	assert.Len(t, testLogger.Entries, 3)
+
+	mockClient := &mockRedisClient{client: r.client}
+	mockClient.PipelinedMock = func(fn func(redis.Pipeliner) error) ([]redis.Cmder, error) {
+		return nil, redis.ErrClosed
+	}
+	r.client = mockClient
+	assert.PanicsWithValue(t, redis.ErrClosed, func() {
+		r.Pipeline(func(pipeline *RedisPipeline) {})
+	})
+}
@@ -0,0 +1,188 @@
+package orm
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/go-redis/redis/v7"
+)
+
+const redisStreamGroup = "orm"
+const redisStreamKeyPrefix = "orm_stream:"
+const redisStreamReclaimIdle = time.Minute
+const redisStreamReclaimInterval = 10 * time.Second
+const redisStreamTrimBatch = 1000
+
+// redisStreamTrimScript removes oldest entries above ARGV[1] that were already acknowledged by consumer group,
+// entries not delivered yet or still pending are never removed
+const redisStreamTrimScript = `
+redis.replicate_commands()
+local excess = redis.call('XLEN', KEYS[1]) - tonumber(ARGV[1])
+if excess <= 0 then
+	return 0
+end
+local lastDelivered = nil
+for _, group in ipairs(redis.call('XINFO', 'GROUPS', KEYS[1])) do
+	local fields = {}
+	for i = 1, #group, 2 do
+		fields[group[i]] = group[i + 1]
+	end
+	if fields['name'] == ARGV[2] then
+		lastDelivered = fields['last-delivered-id']
+	end
+end
+if not lastDelivered or lastDelivered == '0-0' then
+	return 0
+end
+local firstPending = redis.call('XPENDING', KEYS[1], ARGV[2])[2]
+local entries = redis.call('XRANGE', KEYS[1], '-', lastDelivered, 'COUNT', math.min(excess, tonumber(ARGV[3])))
+local ids = {}
+for _, entry in ipairs(entries) do
+	if entry[1] == firstPending then
+		break
+	end
+	ids[#ids + 1] = entry[1]
+end
+if #ids == 0 then
+	return 0
+end
+return redis.call('XDEL', KEYS[1], unpack(ids))
+`
+
+type redisStreamsConfig struct {
+	pool   string
+	maxLen int64
+}
+
+type redisStreamQueueConfig struct {
+	name          string
+	pool          string
+	prefetchCount int
+	maxLen        int64
+}
+
+type redisStreamQueueProvider struct {
+	queues map[string]*redisStreamQueueConfig
+}
+
+func (p *redisStreamQueueProvider) GetQueue(engine *Engine, queueName string, _ int) Queue {
+	config, has := p.queues[queueName]
+	if has {
+		return &redisStreamQueue{engine: engine, config: config}
+	}
+	return &rabbitMQORMQueue{engine.GetRabbitMQQueue(queueName)}
+}
+
+type redisStreamQueue struct {
+	engine *Engine
+	config *redisStreamQueueConfig
+}
+
+func (q *redisStreamQueue) stream() string {
+	return redisStreamKeyPrefix + q.config.name
+}
+
+func (q *redisStreamQueue) Publish(body []byte) {
+	q.engine.GetRedis(q.config.pool).XAdd(&redis.XAddArgs{Stream: q.stream(), Values: map[string]interface{}{"b": body}})
+}
+
+func (q *redisStreamQueue) NewConsumer(name string) QueueConsumer {
+	return &redisStreamConsumer{queue: q, name: name + "_" + newInstanceID(), maxLoopDuration: time.Second}
+}
+
+type redisStreamConsumer struct {
+	queue           *redisStreamQueue
+	name            string
+	disableLoop     int32
+	grouped         int32
+	maxLoopDuration time.Duration
+	heartBeat       func()
+}
+
+// Close removes consumer from group, consumer with pending messages is kept so these messages can be claimed later
+func (c *redisStreamConsumer) Close() {
+	if atomic.LoadInt32(&c.grouped) == 0 {
+		return
+	}
+	r := c.queue.engine.GetRedis(c.queue.config.pool)
+	stream := c.queue.stream()
+	pending := r.XPendingExt(&redis.XPendingExtArgs{Stream: stream, Group: redisStreamGroup, Start: "-", End: "+",
+		Count: 1, Consumer: c.name})
+	if len(pending) == 0 {
+		r.XGroupDelConsumer(stream, redisStreamGroup, c.name)
+	}
+}
+
+func (c *redisStreamConsumer) DisableLoop() {
+	atomic.StoreInt32(&c.disableLoop, 1)
+}
+
+func (c *redisStreamConsumer) SetHeartBeat(beat func()) {
+	c.heartBeat = beat
+}
+
+func (c *redisStreamConsumer) Consume(handler func(items [][]byte)) {
+	r := c.queue.engine.GetRedis(c.queue.config.pool)
+	stream := c.queue.stream()
+	r.XGroupCreateMkStream(stream, redisStreamGroup, "0")
+	atomic.StoreInt32(&c.grouped, 1)
+	max := c.queue.config.prefetchCount
+	if max <= 0 {
+		max = 1
+	}
+	lastHeartBeat := time.Now()
+	var lastReclaim time.Time
+	for {
+		var messages []redis.XMessage
+		if time.Since(lastReclaim) >= redisStreamReclaimInterval {
+			messages = c.reclaim(r, stream, max)
+			lastReclaim = time.Now()
+		}
+		if len(messages) == 0 {
+			streams := r.XReadGroup(&redis.XReadGroupArgs{Group: redisStreamGroup, Consumer: c.name,
+				Streams: []string{stream, ">"}, Count: int64(max), Block: c.maxLoopDuration})
+			if len(streams) > 0 {
+				messages = streams[0].Messages
+			}
+		}
+		if len(messages) > 0 {
+			items := make([][]byte, len(messages))
+			ids := make([]string, len(messages))
+			for i, message := range messages {
+				body, _ := message.Values["b"].(string)
+				items[i] = []byte(body)
+				ids[i] = message.ID
+			}
+			handler(items)
+			r.XAck(stream, redisStreamGroup, ids...)
+			if c.queue.config.maxLen > 0 {
+				r.Eval(redisStreamTrimScript, []string{stream}, c.queue.config.maxLen, redisStreamGroup, redisStreamTrimBatch)
+			}
+		}
+		if atomic.LoadInt32(&c.disableLoop) == 1 {
+			return
+		}
+		if c.heartBeat != nil && time.Since(lastHeartBeat) >= time.Minute {
+			c.heartBeat()
+			lastHeartBeat = time.Now()
+		}
+	}
+}
+
+func (c *redisStreamConsumer) reclaim(r *RedisCache, stream string, max int) []redis.XMessage {
+	pending := r.XPendingExt(&redis.XPendingExtArgs{Stream: stream, Group: redisStreamGroup, Start: "-", End: "+", Count: 100})
+	ids := make([]string, 0)
+	for _, entry := range pending {
+		if entry.Idle >= redisStreamReclaimIdle {
+			ids = append(ids, entry.ID)
+			if len(ids) == max {
+				break
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.XClaim(&redis.XClaimArgs{Stream: stream, Group: redisStreamGroup, Consumer: c.name,
+		MinIdle: redisStreamReclaimIdle, Messages: ids})
+}
@@ -0,0 +1,99 @@
+package orm
+
+import (
+	"testing"
+
+	log2 "github.com/apex/log"
+	"github.com/apex/log/handlers/memory"
+	"github.com/go-redis/redis/v7"
+	"github.com/stretchr/testify/assert"
+)
+
+type testEntityRedisStreamQueue struct {
+	ORM  `orm:"redisCache"`
+	ID   uint
+	Name string
+}
+
+type testEntityRedisStreamQueueDirty struct {
+	ORM  `orm:"dirty=test"`
+	ID   uint
+	Name string
+}
+
+func TestRedisStreamQueue(t *testing.T) {
+	var entity testEntityRedisStreamQueue
+	var dirtyEntity testEntityRedisStreamQueueDirty
+	registry := &Registry{}
+	registry.RegisterDirtyQueue("test", 10)
+	registry.RegisterRedisStreamQueues(1000, "default_queue")
+	engine := PrepareTables(t, registry, entity, dirtyEntity)
+	assert.IsType(t, &redisStreamQueue{}, engine.getQueue(lazyQueueName))
+	assert.IsType(t, &redisStreamQueue{}, engine.getQueue(flushCacheQueueName))
+	assert.IsType(t, &redisStreamQueue{}, engine.getQueue("dirty_queue_test"))
+
+	DBLogger := memory.New()
+	engine.AddQueryLogger(DBLogger, log2.InfoLevel, QueryLoggerSourceDB)
+	engine.Track(&testEntityRedisStreamQueue{Name: "a"}, &testEntityRedisStreamQueue{Name: "b"})
+	engine.FlushLazy()
+	assert.Len(t, DBLogger.Entries, 0)
+	stream := redisStreamKeyPrefix + lazyQueueName
+	assert.Equal(t, int64(1), engine.GetRedis("default_queue").XLen(stream))
+
+	receiver := NewLazyReceiver(engine)
+	receiver.DisableLoop()
+	receiver.Digest()
+	assert.Len(t, DBLogger.Entries, 1)
+	found := engine.LoadByID(2, &entity)
+	assert.True(t, found)
+	assert.Equal(t, "b", entity.Name)
+	pending := engine.GetRedis("default_queue").XPendingExt(&redis.XPendingExtArgs{Stream: stream,
+		Group: redisStreamGroup, Start: "-", End: "+", Count: 10})
+	assert.Len(t, pending, 0)
+
+	queries := len(DBLogger.Entries)
+	receiver.Digest()
+	assert.Len(t, DBLogger.Entries, queries)
+
+	engine.Track(&testEntityRedisStreamQueueDirty{Name: "a"}, &testEntityRedisStreamQueueDirty{Name: "b"})
+	engine.Flush()
+	dirtyReceiver := NewDirtyReceiver(engine)
+	dirtyReceiver.DisableLoop()
+	valid := false
+	dirtyReceiver.Digest("test", func(data []*DirtyData) {
+		valid = true
+		assert.Len(t, data, 2)
+		assert.Equal(t, uint64(1), data[0].ID)
+		assert.True(t, data[0].Added)
+	})
+	assert.True(t, valid)
+}
+
+func TestRedisStreamQueueTrim(t *testing.T) {
+	var entity testEntityRedisStreamQueue
+	registry := &Registry{}
+	registry.RegisterRedisStreamQueues(2, "default_queue")
+	engine := PrepareTables(t, registry, entity)
+	queue := &redisStreamQueue{engine: engine, config: &redisStreamQueueConfig{name: "trim_test", pool: "default_queue",
+		prefetchCount: 2, maxLen: 2}}
+	r := engine.GetRedis("default_queue")
+	r.Del(queue.stream())
+	for i := 0; i < 5; i++ {
+		queue.Publish([]byte("test"))
+	}
+	assert.Equal(t, int64(5), r.XLen(queue.stream()))
+
+	consumer := queue.NewConsumer("test")
+	consumer.DisableLoop()
+	consumer.Consume(func(items [][]byte) {
+		assert.Len(t, items, 2)
+	})
+	assert.Equal(t, int64(3), r.XLen(queue.stream()))
+	consumer.Consume(func(items [][]byte) {
+		assert.Len(t, items, 2)
+	})
+	assert.Equal(t, int64(2), r.XLen(queue.stream()))
+	consumer.Close()
+	consumers := r.Eval("return #redis.call('XINFO', 'CONSUMERS', KEYS[1], ARGV[1])", []string{queue.stream()}, redisStreamGroup)
+	assert.Equal(t, int64(0), consumers)
+}
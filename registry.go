@@ -10,7 +10,6 @@ import (
 	"time"
 
 	"github.com/go-redis/redis/v7"
-	"github.com/golang/groupcache/lru"
 	"github.com/jmoiron/sqlx"
 	"github.com/juju/errors"
 	"github.com/olivere/elastic/v7"
@@ -29,10 +28,16 @@ type Registry struct {
 	enums                map[string]Enum
 	dirtyQueues          map[string]int
 	locks                map[string]string
+
+	localCacheInvalidation string
+	stampedeLockTTL        time.Duration
+	cacheCodec             *cacheCodecConfig
+	redisStreams           *redisStreamsConfig
+	queueProvider          QueueProvider
 }
 
 func (r *Registry) Validate() (ValidatedRegistry, error) {
-	registry := &validatedRegistry{}
+	registry := &validatedRegistry{stampedeLockTTL: r.stampedeLockTTL, cacheCodec: r.cacheCodec}
 	l := len(r.entities)
 	registry.tableSchemas = make(map[reflect.Type]*tableSchema, l)
 	registry.entities = make(map[string]reflect.Type)
@@ -55,6 +60,11 @@ func (r *Registry) Validate() (ValidatedRegistry, error) {
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
+		var maxAllowedPacket int
+		err = db.QueryRow("SHOW VARIABLES LIKE 'max_allowed_packet'").Scan(&skip, &maxAllowedPacket)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
 		maxConnections = int(math.Floor(float64(maxConnections) * 0.9))
 		if maxConnections == 0 {
 			maxConnections = 1
@@ -71,6 +81,7 @@ func (r *Registry) Validate() (ValidatedRegistry, error) {
 		db.SetMaxIdleConns(maxIdleConnections)
 		db.SetConnMaxLifetime(time.Duration(waitTimeout) * time.Second)
 		v.db = db
+		v.maxAllowedPacket = int(math.Floor(float64(maxAllowedPacket) * 0.9))
 		registry.sqlClients[k] = v
 	}
 	if registry.clickHouseClients == nil {
@@ -164,6 +175,9 @@ func (r *Registry) Validate() (ValidatedRegistry, error) {
 					return nil, errors.Errorf("rabbitMQ router name '%s' is not registered", def.Router)
 				}
 			}
+			if def.MaxRetries > 0 && !def.DeadLetter {
+				return nil, errors.Errorf("rabbitMQ queue '%s' with MaxRetries requires DeadLetter", def.Name)
+			}
 			if def.Delayed && def.Router == "" {
 				if registry.rabbitMQRouterConfigs == nil {
 					registry.rabbitMQRouterConfigs = make(map[string]*RabbitMQRouterConfig)
@@ -199,44 +213,87 @@ func (r *Registry) Validate() (ValidatedRegistry, error) {
 			hasLog = true
 		}
 	}
-	if hasLog && registry.rabbitMQChannelsToQueue[logQueueName] == nil {
-		connection, has := registry.rabbitMQServers["default"]
-		if !has {
-			return nil, errors.Errorf("missing default rabbitMQ connection to handle entity change log")
-		}
-		def := &RabbitMQQueueConfig{Name: logQueueName, Durable: true}
-		registry.rabbitMQChannelsToQueue[logQueueName] = &rabbitMQChannelToQueue{connection: connection, config: def}
+	registry.queues = map[string]int{lazyQueueName: 0, flushCacheQueueName: 0}
+	if hasLog {
+		registry.queues[logQueueName] = 0
 	}
-	if registry.rabbitMQChannelsToQueue[lazyQueueName] == nil {
-		connection, has := registry.rabbitMQServers["default"]
-		if !has {
-			return nil, errors.Errorf("missing default rabbitMQ connection to handle lazyFlush")
-		}
-		def := &RabbitMQQueueConfig{Name: lazyQueueName, Durable: true}
-		registry.rabbitMQChannelsToQueue[lazyQueueName] = &rabbitMQChannelToQueue{connection: connection, config: def}
+	for name, max := range registry.GetDirtyQueues() {
+		registry.queues["dirty_queue_"+name] = max
 	}
-	if registry.rabbitMQChannelsToQueue[flushCacheQueueName] == nil {
-		connection, has := registry.rabbitMQServers["default"]
+	if r.queueProvider != nil {
+		registry.queueProvider = r.queueProvider
+	} else if r.redisStreams != nil {
+		_, has := registry.redisServers[r.redisStreams.pool]
 		if !has {
-			return nil, errors.Errorf("missing default rabbitMQ connection to handle flushInCache")
+			return nil, errors.Errorf("redis pool '%s' is not registered", r.redisStreams.pool)
+		}
+		provider := &redisStreamQueueProvider{queues: make(map[string]*redisStreamQueueConfig)}
+		for name, prefetchCount := range registry.queues {
+			if registry.rabbitMQChannelsToQueue[name] == nil {
+				provider.queues[name] = &redisStreamQueueConfig{name: name, pool: r.redisStreams.pool,
+					prefetchCount: prefetchCount, maxLen: r.redisStreams.maxLen}
+			}
+		}
+		registry.queueProvider = provider
+	} else {
+		registry.queueProvider = &rabbitMQQueueProvider{}
+		if hasLog && registry.rabbitMQChannelsToQueue[logQueueName] == nil {
+			connection, has := registry.rabbitMQServers["default"]
+			if !has {
+				return nil, errors.Errorf("missing default rabbitMQ connection to handle entity change log")
+			}
+			def := &RabbitMQQueueConfig{Name: logQueueName, Durable: true}
+			registry.rabbitMQChannelsToQueue[logQueueName] = &rabbitMQChannelToQueue{connection: connection, config: def}
+		}
+		if registry.rabbitMQChannelsToQueue[lazyQueueName] == nil {
+			connection, has := registry.rabbitMQServers["default"]
+			if !has {
+				return nil, errors.Errorf("missing default rabbitMQ connection to handle lazyFlush")
+			}
+			def := &RabbitMQQueueConfig{Name: lazyQueueName, Durable: true}
+			registry.rabbitMQChannelsToQueue[lazyQueueName] = &rabbitMQChannelToQueue{connection: connection, config: def}
+		}
+		if registry.rabbitMQChannelsToQueue[flushCacheQueueName] == nil {
+			connection, has := registry.rabbitMQServers["default"]
+			if !has {
+				return nil, errors.Errorf("missing default rabbitMQ connection to handle flushInCache")
+			}
+			def := &RabbitMQQueueConfig{Name: flushCacheQueueName, Durable: true}
+			registry.rabbitMQChannelsToQueue[flushCacheQueueName] = &rabbitMQChannelToQueue{connection: connection, config: def}
+		}
+		queues := registry.GetDirtyQueues()
+		if len(queues) > 0 {
+			connection, has := registry.rabbitMQServers["default"]
+			if !has {
+				return nil, errors.Errorf("missing default rabbitMQ connection to handle flushInCache")
+			}
+			for name, max := range registry.GetDirtyQueues() {
+				queueName := "dirty_queue_" + name
+				def := &RabbitMQQueueConfig{Name: queueName, Durable: false, PrefetchCount: max}
+				registry.rabbitMQChannelsToQueue[queueName] = &rabbitMQChannelToQueue{connection: connection, config: def}
+			}
 		}
-		def := &RabbitMQQueueConfig{Name: flushCacheQueueName, Durable: true}
-		registry.rabbitMQChannelsToQueue[flushCacheQueueName] = &rabbitMQChannelToQueue{connection: connection, config: def}
 	}
-	queues := registry.GetDirtyQueues()
-	if len(queues) > 0 {
-		connection, has := registry.rabbitMQServers["default"]
+	if r.localCacheInvalidation != "" {
+		connection, has := registry.rabbitMQServers[r.localCacheInvalidation]
 		if !has {
-			return nil, errors.Errorf("missing default rabbitMQ connection to handle flushInCache")
+			return nil, errors.Errorf("rabbitMQ server '%s' is not registered", r.localCacheInvalidation)
 		}
-		for name, max := range registry.GetDirtyQueues() {
-			queueName := "dirty_queue_" + name
-			def := &RabbitMQQueueConfig{Name: queueName, Durable: false, PrefetchCount: max}
-			registry.rabbitMQChannelsToQueue[queueName] = &rabbitMQChannelToQueue{connection: connection, config: def}
+		registry.localCacheInvalidationInstance = newInstanceID()
+		registry.rabbitMQRouterConfigs[localCacheInvalidationRouterName] = &RabbitMQRouterConfig{Name: localCacheInvalidationRouterName, Type: "fanout"}
+		def := &RabbitMQQueueConfig{Name: localCacheInvalidationQueueName + "_" + registry.localCacheInvalidationInstance,
+			Router: localCacheInvalidationRouterName, AutoDelete: true}
+		registry.rabbitMQChannelsToQueue[localCacheInvalidationQueueName] = &rabbitMQChannelToQueue{connection: connection, config: def}
+	}
+	engine = registry.CreateEngine()
+	streams, isRedisStreams := registry.queueProvider.(*redisStreamQueueProvider)
+	if isRedisStreams {
+		for _, config := range streams.queues {
+			stream := &redisStreamQueue{engine: engine, config: config}
+			engine.GetRedis(config.pool).XGroupCreateMkStream(stream.stream(), redisStreamGroup, "0")
 		}
 	}
 	//init rabbitMQ channels
-	engine = registry.CreateEngine()
 	for code, config := range registry.rabbitMQChannelsToQueue {
 		if config.config.Router == "" {
 			if config.config.Delayed {
@@ -256,7 +313,8 @@ func (r *Registry) Validate() (ValidatedRegistry, error) {
 			}
 		} else {
 			r := engine.GetRabbitMQRouter(code)
-			receiver := r.initChannel(config.config.Name, false)
+			//queue for local cache invalidation is declared by its receiver
+			receiver := r.initChannel(config.config.Name, code == localCacheInvalidationQueueName)
 			err := receiver.Close()
 			if err != nil {
 				panic(err)
@@ -326,6 +384,10 @@ func (r *Registry) RegisterElasticPool(url string, code ...string) {
 }
 
 func (r *Registry) RegisterLocalCache(size int, code ...string) {
+	r.RegisterLocalCacheWithTTL(size, 0, code...)
+}
+
+func (r *Registry) RegisterLocalCacheWithTTL(size int, ttlSeconds int, code ...string) {
 	dbCode := "default"
 	if len(code) > 0 {
 		dbCode = code[0]
@@ -333,7 +395,7 @@ func (r *Registry) RegisterLocalCache(size int, code ...string) {
 	if r.localCacheContainers == nil {
 		r.localCacheContainers = make(map[string]*LocalCacheConfig)
 	}
-	r.localCacheContainers[dbCode] = &LocalCacheConfig{code: dbCode, lru: lru.New(size)}
+	r.localCacheContainers[dbCode] = &LocalCacheConfig{code: dbCode, storage: newLocalCacheStorage(size), ttl: int64(ttlSeconds)}
 }
 
 func (r *Registry) RegisterRedis(address string, db int, code ...string) {
@@ -365,7 +427,39 @@ func (r *Registry) RegisterRedisRing(addresses []string, db int, code ...string)
 	if len(code) > 0 {
 		dbCode = code[0]
 	}
-	redisCache := &RedisCacheConfig{code: dbCode, ring: ring}
+	redisCache := &RedisCacheConfig{code: dbCode, client: ring}
+	if r.redisServers == nil {
+		r.redisServers = make(map[string]*RedisCacheConfig)
+	}
+	r.redisServers[dbCode] = redisCache
+}
+
+func (r *Registry) RegisterRedisSentinel(masterName string, sentinelAddrs []string, db int, code ...string) {
+	client := redis.NewFailoverClient(&redis.FailoverOptions{
+		MasterName:    masterName,
+		SentinelAddrs: sentinelAddrs,
+		DB:            db,
+	})
+	dbCode := "default"
+	if len(code) > 0 {
+		dbCode = code[0]
+	}
+	redisCache := &RedisCacheConfig{code: dbCode, client: client}
+	if r.redisServers == nil {
+		r.redisServers = make(map[string]*RedisCacheConfig)
+	}
+	r.redisServers[dbCode] = redisCache
+}
+
+func (r *Registry) RegisterRedisCluster(addresses []string, code ...string) {
+	cluster := redis.NewClusterClient(&redis.ClusterOptions{
+		Addrs: addresses,
+	})
+	dbCode := "default"
+	if len(code) > 0 {
+		dbCode = code[0]
+	}
+	redisCache := &RedisCacheConfig{code: dbCode, client: cluster, cluster: cluster}
 	if r.redisServers == nil {
 		r.redisServers = make(map[string]*RedisCacheConfig)
 	}
@@ -384,6 +478,16 @@ func (r *Registry) RegisterRabbitMQServer(address string, code ...string) {
 	r.rabbitMQServers[dbCode] = rabbitMQ
 }
 
+func (r *Registry) RegisterRabbitMQServerWithConfirms(address string, confirmTimeout time.Duration, code ...string) {
+	r.RegisterRabbitMQServer(address, code...)
+	dbCode := "default"
+	if len(code) > 0 {
+		dbCode = code[0]
+	}
+	r.rabbitMQServers[dbCode].confirm = true
+	r.rabbitMQServers[dbCode].confirmTimeout = confirmTimeout
+}
+
 func (r *Registry) RegisterRabbitMQQueue(config *RabbitMQQueueConfig, serverPool ...string) {
 	dbCode := "default"
 	if len(serverPool) > 0 {
@@ -419,6 +523,34 @@ func (r *Registry) RegisterDirtyQueue(code string, batchSize int) {
 	r.dirtyQueues[code] = batchSize
 }
 
+func (r *Registry) RegisterLocalCacheInvalidation(serverPool ...string) {
+	pool := "default"
+	if len(serverPool) > 0 {
+		pool = serverPool[0]
+	}
+	r.localCacheInvalidation = pool
+}
+
+func (r *Registry) RegisterCacheStampedeLock(ttl time.Duration) {
+	r.stampedeLockTTL = ttl
+}
+
+func (r *Registry) RegisterMsgpackCacheCodec(compressThreshold int) {
+	r.cacheCodec = &cacheCodecConfig{compressThreshold: compressThreshold}
+}
+
+func (r *Registry) RegisterRedisStreamQueues(maxLen int64, redisPool ...string) {
+	pool := "default"
+	if len(redisPool) > 0 {
+		pool = redisPool[0]
+	}
+	r.redisStreams = &redisStreamsConfig{pool: pool, maxLen: maxLen}
+}
+
+func (r *Registry) RegisterQueueProvider(provider QueueProvider) {
+	r.queueProvider = provider
+}
+
 func (r *Registry) RegisterLocker(code string, redisCode string) {
 	if r.locks == nil {
 		r.locks = make(map[string]string)
@@ -476,9 +608,10 @@ func (r *Registry) RegisterClickHouse(url string, code ...string) {
 }
 
 type RedisCacheConfig struct {
-	code   string
-	client *redis.Client
-	ring   *redis.Ring
+	code    string
+	client  redis.UniversalClient
+	cluster *redis.ClusterClient
+	group   singleFlight
 }
 
 type ElasticConfig struct {
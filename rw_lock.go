@@ -0,0 +1,100 @@
+package orm
+
+import "time"
+
+const rwLockKeyPrefix = "orm_rw_lock:"
+const rwLockWriterPendingTTL = 512
+
+const rwLockObtainReadScript = leaseScriptNow + `
+local expires = now + tonumber(ARGV[1])
+local maxExpires = expires
+local fields = redis.call('HGETALL', KEYS[1])
+for i = 1, #fields, 2 do
+	local fieldExpires = tonumber(fields[i + 1])
+	if fieldExpires <= now then
+		redis.call('HDEL', KEYS[1], fields[i])
+	else
+		if string.sub(fields[i], 1, 2) ~= 'r:' then
+			return 0
+		end
+		maxExpires = math.max(maxExpires, fieldExpires)
+	end
+end
+redis.call('HSET', KEYS[1], ARGV[2], expires)
+redis.call('PEXPIREAT', KEYS[1], maxExpires)
+return 1
+`
+
+const rwLockObtainWriteScript = leaseScriptNow + `
+local expires = now + tonumber(ARGV[1])
+local maxExpires = expires
+local readers = false
+local fields = redis.call('HGETALL', KEYS[1])
+for i = 1, #fields, 2 do
+	local fieldExpires = tonumber(fields[i + 1])
+	if fieldExpires <= now then
+		redis.call('HDEL', KEYS[1], fields[i])
+	else
+		local prefix = string.sub(fields[i], 1, 2)
+		if prefix == 'w:' then
+			return 0
+		end
+		if prefix == 'r:' then
+			readers = true
+		end
+		maxExpires = math.max(maxExpires, fieldExpires)
+	end
+end
+if readers then
+	local pending = now + tonumber(ARGV[3])
+	redis.call('HSET', KEYS[1], 'p', pending)
+	redis.call('PEXPIREAT', KEYS[1], math.max(maxExpires, pending))
+	return 0
+end
+redis.call('HDEL', KEYS[1], 'p')
+redis.call('HSET', KEYS[1], ARGV[2], expires)
+redis.call('PEXPIREAT', KEYS[1], maxExpires)
+return 1
+`
+
+const rwLockRefreshScript = leaseScriptNow + `
+local expires = now + tonumber(ARGV[2])
+local current = tonumber(redis.call('HGET', KEYS[1], ARGV[1]))
+if current == nil or current <= now then
+	return 0
+end
+redis.call('HSET', KEYS[1], ARGV[1], expires)
+if redis.call('PTTL', KEYS[1]) < tonumber(ARGV[2]) then
+	redis.call('PEXPIREAT', KEYS[1], expires)
+end
+return 1
+`
+
+const rwLockReleaseScript = `
+return redis.call('HDEL', KEYS[1], ARGV[1])
+`
+
+type RWLock struct {
+	redisLease
+}
+
+// ObtainRead obtains shared lock, many readers can hold it at once but never together with writer
+func (l *Locker) ObtainRead(key string, ttl time.Duration, waitTimeout time.Duration) (lock *RWLock, obtained bool) {
+	return l.obtainRWLock("[ORM][LOCKER][OBTAIN_READ]", key, "r:", ttl, waitTimeout, rwLockObtainReadScript)
+}
+
+// ObtainWrite obtains exclusive lock, new readers are blocked while writer is waiting
+func (l *Locker) ObtainWrite(key string, ttl time.Duration, waitTimeout time.Duration) (lock *RWLock, obtained bool) {
+	return l.obtainRWLock("[ORM][LOCKER][OBTAIN_WRITE]", key, "w:", ttl, waitTimeout, rwLockObtainWriteScript)
+}
+
+func (l *Locker) obtainRWLock(message string, key string, prefix string, ttl time.Duration, waitTimeout time.Duration,
+	script string) (lock *RWLock, obtained bool) {
+	redisKey := rwLockKeyPrefix + key
+	member := prefix + newLeaseToken()
+	if !l.obtainLease(message, redisKey, ttl, waitTimeout, script, member, rwLockWriterPendingTTL) {
+		return nil, false
+	}
+	return &RWLock{redisLease{locker: l, key: redisKey, member: member, has: true,
+		refreshScript: rwLockRefreshScript, releaseScript: rwLockReleaseScript}}, true
+}
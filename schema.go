@@ -500,9 +500,14 @@ func getForeignKeys(engine *Engine, createTableDB string, tableName string, pool
 		for _, line := range strings.Split(createTableDB, "\n") {
 			line = strings.TrimSpace(strings.TrimRight(line, ","))
 			if strings.Index(line, fmt.Sprintf("CONSTRAINT `%s`", row.ConstraintName)) == 0 {
-				words := strings.Split(line, " ")
-				if strings.ToUpper(words[len(words)-2]) == "DELETE" {
-					row.OnDelete = strings.ToUpper(words[len(words)-1])
+				pos := strings.Index(line, " ON DELETE ")
+				if pos > 0 {
+					onDelete := line[pos+11:]
+					end := strings.Index(onDelete, " ON UPDATE ")
+					if end > 0 {
+						onDelete = onDelete[:end]
+					}
+					row.OnDelete = strings.ToUpper(onDelete)
 				}
 			}
 		}
@@ -584,11 +589,7 @@ func checkColumn(engine *Engine, schema *tableSchema, t reflect.Type, field *ref
 		if key == "index" && field.Type.Kind() == reflect.Ptr {
 			refOneSchema = getTableSchema(engine.registry, field.Type.Elem())
 			if refOneSchema != nil {
-				onDelete := "RESTRICT"
-				_, hasCascade := attributes["cascade"]
-				if hasCascade {
-					onDelete = "CASCADE"
-				}
+				onDelete := schema.getOnDelete(columnName)
 				pool := refOneSchema.GetMysql(engine)
 				foreignKey := &foreignIndex{Column: field.Name, Table: refOneSchema.tableName,
 					ParentDatabase: pool.GetDatabaseName(), OnDelete: onDelete}
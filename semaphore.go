@@ -0,0 +1,55 @@
+package orm
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+)
+
+const semaphoreKeyPrefix = "orm_semaphore:"
+
+const semaphoreObtainScript = leaseScriptNow + `
+local expires = now + tonumber(ARGV[1])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now)
+if redis.call('ZCARD', KEYS[1]) >= tonumber(ARGV[3]) then
+	return 0
+end
+redis.call('ZADD', KEYS[1], expires, ARGV[2])
+local last = redis.call('ZRANGE', KEYS[1], -1, -1, 'WITHSCORES')
+redis.call('PEXPIREAT', KEYS[1], tonumber(last[2]))
+return 1
+`
+
+const semaphoreRefreshScript = leaseScriptNow + `
+local expires = now + tonumber(ARGV[2])
+local current = tonumber(redis.call('ZSCORE', KEYS[1], ARGV[1]))
+if current == nil or current <= now then
+	return 0
+end
+redis.call('ZADD', KEYS[1], expires, ARGV[1])
+local last = redis.call('ZRANGE', KEYS[1], -1, -1, 'WITHSCORES')
+redis.call('PEXPIREAT', KEYS[1], tonumber(last[2]))
+return 1
+`
+
+const semaphoreReleaseScript = `
+return redis.call('ZREM', KEYS[1], ARGV[1])
+`
+
+type SemaphorePermit struct {
+	redisLease
+}
+
+// AcquireSemaphore obtains one of limit permits, permits not released in ttl are returned automatically
+func (l *Locker) AcquireSemaphore(key string, limit int, ttl time.Duration, waitTimeout time.Duration) (permit *SemaphorePermit, obtained bool) {
+	if limit <= 0 {
+		panic(errors.NotValidf("limit must be greater than zero"))
+	}
+	redisKey := semaphoreKeyPrefix + key
+	member := newLeaseToken()
+	if !l.obtainLease("[ORM][LOCKER][ACQUIRE_SEMAPHORE]", redisKey, ttl, waitTimeout, semaphoreObtainScript, member, limit) {
+		return nil, false
+	}
+	return &SemaphorePermit{redisLease{locker: l, key: redisKey, member: member, has: true,
+		refreshScript: semaphoreRefreshScript, releaseScript: semaphoreReleaseScript}}, true
+}
@@ -0,0 +1,50 @@
+package orm
+
+import "sync"
+
+type singleFlightCall struct {
+	wg      sync.WaitGroup
+	value   interface{}
+	recover interface{}
+}
+
+type singleFlight struct {
+	mutex sync.Mutex
+	calls map[string]*singleFlightCall
+}
+
+func (g *singleFlight) do(key string, fn func() interface{}) (value interface{}, shared bool) {
+	if g == nil {
+		return fn(), false
+	}
+	g.mutex.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*singleFlightCall)
+	}
+	call, has := g.calls[key]
+	if has {
+		g.mutex.Unlock()
+		call.wg.Wait()
+		if call.recover != nil {
+			panic(call.recover)
+		}
+		return call.value, true
+	}
+	call = &singleFlightCall{}
+	call.wg.Add(1)
+	g.calls[key] = call
+	g.mutex.Unlock()
+
+	defer func() {
+		call.recover = recover()
+		g.mutex.Lock()
+		delete(g.calls, key)
+		g.mutex.Unlock()
+		call.wg.Done()
+		if call.recover != nil {
+			panic(call.recover)
+		}
+	}()
+	call.value = fn()
+	return call.value, false
+}
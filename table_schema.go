@@ -6,20 +6,27 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/juju/errors"
 
 	"github.com/segmentio/fasthash/fnv1a"
 )
 
+var cachedQueryOrderByIDRegexp = regexp.MustCompile(`(?i)^order by\s+:ID(\s+asc)?\s*$`)
+
 type CachedQuery struct{}
 
 type cachedQueryDefinition struct {
-	Max           int
-	Query         string
-	TrackedFields []string
-	QueryFields   []string
-	OrderFields   []string
+	Max             int
+	Query           string
+	TrackedFields   []string
+	QueryFields     []string
+	OrderFields     []string
+	Ranges          bool
+	BucketFields    []string
+	BucketArguments []int
+	Patchable       bool
 }
 
 type Enum interface {
@@ -97,6 +104,8 @@ type tableSchema struct {
 	columnsStamp     string
 	localCacheName   string
 	redisCacheName   string
+	localTTL         int
+	redisTTL         int
 	cachePrefix      string
 	hasFakeDelete    bool
 	hasLog           bool
@@ -211,6 +220,23 @@ func (tableSchema *tableSchema) GetUsage(registry ValidatedRegistry) map[reflect
 	return results
 }
 
+func (tableSchema *tableSchema) getOnDelete(columnName string) string {
+	attributes := tableSchema.tags[columnName]
+	switch attributes["onDelete"] {
+	case "setNull":
+		return "SET NULL"
+	case "cascade":
+		return "CASCADE"
+	case "restrict":
+		return "RESTRICT"
+	}
+	_, hasCascade := attributes["cascade"]
+	if hasCascade {
+		return "CASCADE"
+	}
+	return "RESTRICT"
+}
+
 func (tableSchema *tableSchema) GetSchemaChanges(engine *Engine) (has bool, alters []Alter) {
 	return getSchemaChanges(engine, tableSchema)
 }
@@ -259,6 +285,15 @@ func initTableSchema(registry *Registry, entityType reflect.Type) (*tableSchema,
 		}
 	}
 
+	localTTL, err := getCacheTTL(tags["ORM"], "localTTL", entityType)
+	if err != nil {
+		return nil, err
+	}
+	redisTTL, err := getCacheTTL(tags["ORM"], "redisTTL", entityType)
+	if err != nil {
+		return nil, err
+	}
+
 	cachePrefix := ""
 	if mysql != "default" {
 		cachePrefix = mysql
@@ -326,6 +361,15 @@ func initTableSchema(registry *Registry, entityType reflect.Type) (*tableSchema,
 				}
 			}
 
+			wherePart := queryOrigin
+			if posOrderBy > -1 {
+				wherePart = queryOrigin[:posOrderBy]
+			}
+			ranges, bucketFields, bucketArguments := getCachedQueryBuckets(wherePart)
+			patchable := !isOne && !ranges && !hasFakeDelete && len(bucketFields) == len(fieldsQuery)
+			if patchable && query != "1 ORDER BY `ID`" {
+				patchable = posOrderBy > -1 && cachedQueryOrderByIDRegexp.MatchString(queryOrigin[posOrderBy:])
+			}
 			if !isOne {
 				max := 50000
 				maxAttribute, has := values["max"]
@@ -336,11 +380,13 @@ func initTableSchema(registry *Registry, entityType reflect.Type) (*tableSchema,
 					}
 					max = maxFromUser
 				}
-				def := &cachedQueryDefinition{max, query, fieldsTracked, fieldsQuery, fieldsOrder}
+				def := &cachedQueryDefinition{max, query, fieldsTracked, fieldsQuery, fieldsOrder,
+					ranges, bucketFields, bucketArguments, patchable}
 				cachedQueries[key] = def
 				cachedQueriesAll[key] = def
 			} else {
-				def := &cachedQueryDefinition{1, query, fieldsTracked, fieldsQuery, fieldsOrder}
+				def := &cachedQueryDefinition{1, query, fieldsTracked, fieldsQuery, fieldsOrder,
+					ranges, bucketFields, bucketArguments, patchable}
 				cachedQueriesOne[key] = def
 				cachedQueriesAll[key] = def
 			}
@@ -348,6 +394,15 @@ func initTableSchema(registry *Registry, entityType reflect.Type) (*tableSchema,
 		_, has = values["ref"]
 		if has {
 			oneRefs = append(oneRefs, key)
+			onDelete, hasOnDelete := values["onDelete"]
+			if hasOnDelete {
+				if onDelete != "restrict" && onDelete != "cascade" && onDelete != "setNull" {
+					return nil, errors.Errorf("invalid onDelete value '%s' for %s in %s", onDelete, key, entityType.String())
+				}
+				if onDelete == "setNull" && values["required"] == "true" {
+					return nil, errors.Errorf("onDelete=setNull not allowed for required reference %s in %s", key, entityType.String())
+				}
+			}
 		}
 	}
 	logPoolName := tags["ORM"]["log"]
@@ -434,6 +489,8 @@ func initTableSchema(registry *Registry, entityType reflect.Type) (*tableSchema,
 		cachedIndexesAll: cachedQueriesAll,
 		localCacheName:   localCache,
 		redisCacheName:   redisCache,
+		localTTL:         localTTL,
+		redisTTL:         redisTTL,
 		refOne:           oneRefs,
 		cachePrefix:      cachePrefix,
 		uniqueIndices:    uniqueIndicesSimple,
@@ -596,6 +653,54 @@ func buildTableFields(t reflect.Type, start int, prefix string, schemaTags map[s
 	return fields
 }
 
+// getCachedQueryBuckets finds fields compared with placeholder using "=" operator, query without other
+// placeholders is cached in one bucket per these fields values
+func getCachedQueryBuckets(query string) (ranges bool, fields []string, arguments []int) {
+	counter := make(map[string]int)
+	candidates := make([]string, 0)
+	positions := make([]int, 0)
+	segmentStart := 0
+	placeholder := 0
+	for i := 0; i < len(query); i++ {
+		if query[i] != '?' {
+			continue
+		}
+		segment := query[segmentStart:i]
+		segmentStart = i + 1
+		placeholder++
+		colon := strings.LastIndexByte(segment, ':')
+		if colon == -1 {
+			ranges = true
+			continue
+		}
+		end := colon + 1
+		for end < len(segment) && isCachedQueryFieldChar(segment[end]) {
+			end++
+		}
+		field := segment[colon+1 : end]
+		counter[field]++
+		if strings.TrimSpace(segment[end:]) == "=" {
+			candidates = append(candidates, field)
+			positions = append(positions, placeholder-1)
+		} else {
+			ranges = true
+		}
+	}
+	fields = make([]string, 0)
+	arguments = make([]int, 0)
+	for i, field := range candidates {
+		if counter[field] == 1 {
+			fields = append(fields, field)
+			arguments = append(arguments, positions[i])
+		}
+	}
+	return ranges, fields, arguments
+}
+
+func isCachedQueryFieldChar(char byte) bool {
+	return (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') || (char >= '0' && char <= '9')
+}
+
 func extractTags(registry *Registry, entityType reflect.Type, prefix string) (fields map[string]map[string]string) {
 	fields = make(map[string]map[string]string)
 	for i := 0; i < entityType.NumField(); i++ {
@@ -665,6 +770,35 @@ func extractTag(registry *Registry, field reflect.StructField) map[string]map[st
 	return make(map[string]map[string]string)
 }
 
+func getCacheTTL(tags map[string]string, key string, entityType reflect.Type) (int, error) {
+	userValue, has := tags[key]
+	if !has {
+		userValue, has = tags["ttl"]
+		if !has {
+			return 0, nil
+		}
+	}
+	ttl, err := strconv.Atoi(userValue)
+	if err != nil || ttl < 0 {
+		return 0, errors.NotValidf("cache ttl '%s' in %s", userValue, entityType.String())
+	}
+	return ttl, nil
+}
+
+func (tableSchema *tableSchema) localCacheValue(value interface{}) interface{} {
+	if tableSchema.localTTL > 0 {
+		return localCacheValue{value: value, expires: time.Now().Unix() + int64(tableSchema.localTTL)}
+	}
+	return value
+}
+
+func (tableSchema *tableSchema) redisNilTTL() int {
+	if tableSchema.redisTTL > 0 && tableSchema.redisTTL < 60 {
+		return tableSchema.redisTTL
+	}
+	return 60
+}
+
 func (tableSchema *tableSchema) getCacheKey(id uint64) string {
 	return tableSchema.cachePrefix + ":" + tableSchema.columnsStamp + ":" + strconv.FormatUint(id, 10)
 }
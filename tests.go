@@ -52,32 +52,59 @@ func PrepareTables(t *testing.T, registry *Registry, entities ...interface{}) *E
 }
 
 type mockRedisClient struct {
-	client      redisClient
-	GetMock     func(key string) (string, error)
-	LRangeMock  func(key string, start, stop int64) ([]string, error)
-	HMGetMock   func(key string, fields ...string) ([]interface{}, error)
-	HGetAllMock func(key string) (map[string]string, error)
-	LPushMock   func(key string, values ...interface{}) (int64, error)
-	LLenMock    func(key string) (int64, error)
-	RPushMock   func(key string, values ...interface{}) (int64, error)
-	RPopMock    func(key string) (string, error)
-	LSetMock    func(key string, index int64, value interface{}) (string, error)
-	LRemMock    func(key string, count int64, value interface{}) (int64, error)
-	LTrimMock   func(key string, start, stop int64) (string, error)
-	ZCardMock   func(key string) (int64, error)
-	SCardMock   func(key string) (int64, error)
-	ZCountMock  func(key string, min, max string) (int64, error)
-	SPopNMock   func(key string, max int64) ([]string, error)
-	SPopMock    func(key string) (string, error)
-	ZAddMock    func(key string, members ...*redis.Z) (int64, error)
-	SAddMock    func(key string, members ...interface{}) (int64, error)
-	HMSetMock   func(key string, fields map[string]interface{}) (bool, error)
-	HSetMock    func(key string, field string, value interface{}) (int64, error)
-	MGetMock    func(keys ...string) ([]interface{}, error)
-	SetMock     func(key string, value interface{}, expiration time.Duration) error
-	MSetMock    func(pairs ...interface{}) error
-	DelMock     func(keys ...string) error
-	FlushDBMock func() error
+	client                   redisClient
+	GetMock                  func(key string) (string, error)
+	LRangeMock               func(key string, start, stop int64) ([]string, error)
+	HMGetMock                func(key string, fields ...string) ([]interface{}, error)
+	HGetAllMock              func(key string) (map[string]string, error)
+	LPushMock                func(key string, values ...interface{}) (int64, error)
+	LLenMock                 func(key string) (int64, error)
+	RPushMock                func(key string, values ...interface{}) (int64, error)
+	RPopMock                 func(key string) (string, error)
+	LSetMock                 func(key string, index int64, value interface{}) (string, error)
+	LRemMock                 func(key string, count int64, value interface{}) (int64, error)
+	LTrimMock                func(key string, start, stop int64) (string, error)
+	ZCardMock                func(key string) (int64, error)
+	SCardMock                func(key string) (int64, error)
+	ZCountMock               func(key string, min, max string) (int64, error)
+	SPopNMock                func(key string, max int64) ([]string, error)
+	SPopMock                 func(key string) (string, error)
+	ZAddMock                 func(key string, members ...*redis.Z) (int64, error)
+	SAddMock                 func(key string, members ...interface{}) (int64, error)
+	HMSetMock                func(key string, fields map[string]interface{}) (bool, error)
+	HSetMock                 func(key string, field string, value interface{}) (int64, error)
+	MGetMock                 func(keys ...string) ([]interface{}, error)
+	SetMock                  func(key string, value interface{}, expiration time.Duration) error
+	SetNXMock                func(key string, value interface{}, expiration time.Duration) (bool, error)
+	MSetMock                 func(pairs ...interface{}) error
+	MSetExMock               func(expiration time.Duration, pairs ...interface{}) error
+	DelMock                  func(keys ...string) error
+	EvalMock                 func(script string, keys []string, args ...interface{}) (interface{}, error)
+	FlushDBMock              func() error
+	IncrMock                 func(key string) (int64, error)
+	IncrByMock               func(key string, value int64) (int64, error)
+	HIncrByMock              func(key, field string, incr int64) (int64, error)
+	HDelMock                 func(key string, fields ...string) (int64, error)
+	ExpireMock               func(key string, expiration time.Duration) (bool, error)
+	TTLMock                  func(key string) (time.Duration, error)
+	ExistsMock               func(keys ...string) (int64, error)
+	ZRangeByScoreMock        func(key string, opt *redis.ZRangeBy) ([]string, error)
+	ZRemMock                 func(key string, members ...interface{}) (int64, error)
+	ZRevRangeMock            func(key string, start, stop int64) ([]string, error)
+	SMembersMock             func(key string) ([]string, error)
+	SIsMemberMock            func(key string, member interface{}) (bool, error)
+	ScanMock                 func(cursor uint64, match string, count int64) ([]string, uint64, error)
+	XAddMock                 func(a *redis.XAddArgs) (string, error)
+	XLenMock                 func(stream string) (int64, error)
+	XGroupCreateMkStreamMock func(stream, group, start string) (string, error)
+	XGroupDelConsumerMock    func(stream, group, consumer string) (int64, error)
+	XReadGroupMock           func(a *redis.XReadGroupArgs) ([]redis.XStream, error)
+	XAckMock                 func(stream, group string, ids ...string) (int64, error)
+	XPendingExtMock          func(a *redis.XPendingExtArgs) ([]redis.XPendingExt, error)
+	XClaimMock               func(a *redis.XClaimArgs) ([]redis.XMessage, error)
+
+	PipelinedMock   func(fn func(redis.Pipeliner) error) ([]redis.Cmder, error)
+	TxPipelinedMock func(fn func(redis.Pipeliner) error) ([]redis.Cmder, error)
 }
 
 func (c *mockRedisClient) Get(key string) (string, error) {
@@ -241,6 +268,20 @@ func (c *mockRedisClient) MSet(pairs ...interface{}) error {
 	return c.client.MSet(pairs...)
 }
 
+func (c *mockRedisClient) SetNX(key string, value interface{}, expiration time.Duration) (bool, error) {
+	if c.SetNXMock != nil {
+		return c.SetNXMock(key, value, expiration)
+	}
+	return c.client.SetNX(key, value, expiration)
+}
+
+func (c *mockRedisClient) MSetEx(expiration time.Duration, pairs ...interface{}) error {
+	if c.MSetExMock != nil {
+		return c.MSetExMock(expiration, pairs...)
+	}
+	return c.client.MSetEx(expiration, pairs...)
+}
+
 func (c *mockRedisClient) Del(keys ...string) error {
 	if c.DelMock != nil {
 		return c.DelMock(keys...)
@@ -248,9 +289,177 @@ func (c *mockRedisClient) Del(keys ...string) error {
 	return c.client.Del(keys...)
 }
 
+func (c *mockRedisClient) Eval(script string, keys []string, args ...interface{}) (interface{}, error) {
+	if c.EvalMock != nil {
+		return c.EvalMock(script, keys, args...)
+	}
+	return c.client.Eval(script, keys, args...)
+}
+
 func (c *mockRedisClient) FlushDB() error {
 	if c.FlushDBMock != nil {
 		return c.FlushDBMock()
 	}
 	return c.client.FlushDB()
 }
+
+func (c *mockRedisClient) Incr(key string) (int64, error) {
+	if c.IncrMock != nil {
+		return c.IncrMock(key)
+	}
+	return c.client.Incr(key)
+}
+
+func (c *mockRedisClient) IncrBy(key string, value int64) (int64, error) {
+	if c.IncrByMock != nil {
+		return c.IncrByMock(key, value)
+	}
+	return c.client.IncrBy(key, value)
+}
+
+func (c *mockRedisClient) HIncrBy(key, field string, incr int64) (int64, error) {
+	if c.HIncrByMock != nil {
+		return c.HIncrByMock(key, field, incr)
+	}
+	return c.client.HIncrBy(key, field, incr)
+}
+
+func (c *mockRedisClient) HDel(key string, fields ...string) (int64, error) {
+	if c.HDelMock != nil {
+		return c.HDelMock(key, fields...)
+	}
+	return c.client.HDel(key, fields...)
+}
+
+func (c *mockRedisClient) Expire(key string, expiration time.Duration) (bool, error) {
+	if c.ExpireMock != nil {
+		return c.ExpireMock(key, expiration)
+	}
+	return c.client.Expire(key, expiration)
+}
+
+func (c *mockRedisClient) TTL(key string) (time.Duration, error) {
+	if c.TTLMock != nil {
+		return c.TTLMock(key)
+	}
+	return c.client.TTL(key)
+}
+
+func (c *mockRedisClient) Exists(keys ...string) (int64, error) {
+	if c.ExistsMock != nil {
+		return c.ExistsMock(keys...)
+	}
+	return c.client.Exists(keys...)
+}
+
+func (c *mockRedisClient) ZRangeByScore(key string, opt *redis.ZRangeBy) ([]string, error) {
+	if c.ZRangeByScoreMock != nil {
+		return c.ZRangeByScoreMock(key, opt)
+	}
+	return c.client.ZRangeByScore(key, opt)
+}
+
+func (c *mockRedisClient) ZRem(key string, members ...interface{}) (int64, error) {
+	if c.ZRemMock != nil {
+		return c.ZRemMock(key, members...)
+	}
+	return c.client.ZRem(key, members...)
+}
+
+func (c *mockRedisClient) ZRevRange(key string, start, stop int64) ([]string, error) {
+	if c.ZRevRangeMock != nil {
+		return c.ZRevRangeMock(key, start, stop)
+	}
+	return c.client.ZRevRange(key, start, stop)
+}
+
+func (c *mockRedisClient) SMembers(key string) ([]string, error) {
+	if c.SMembersMock != nil {
+		return c.SMembersMock(key)
+	}
+	return c.client.SMembers(key)
+}
+
+func (c *mockRedisClient) SIsMember(key string, member interface{}) (bool, error) {
+	if c.SIsMemberMock != nil {
+		return c.SIsMemberMock(key, member)
+	}
+	return c.client.SIsMember(key, member)
+}
+
+func (c *mockRedisClient) Scan(cursor uint64, match string, count int64) ([]string, uint64, error) {
+	if c.ScanMock != nil {
+		return c.ScanMock(cursor, match, count)
+	}
+	return c.client.Scan(cursor, match, count)
+}
+
+func (c *mockRedisClient) XAdd(a *redis.XAddArgs) (string, error) {
+	if c.XAddMock != nil {
+		return c.XAddMock(a)
+	}
+	return c.client.XAdd(a)
+}
+
+func (c *mockRedisClient) XLen(stream string) (int64, error) {
+	if c.XLenMock != nil {
+		return c.XLenMock(stream)
+	}
+	return c.client.XLen(stream)
+}
+
+func (c *mockRedisClient) XGroupCreateMkStream(stream, group, start string) (string, error) {
+	if c.XGroupCreateMkStreamMock != nil {
+		return c.XGroupCreateMkStreamMock(stream, group, start)
+	}
+	return c.client.XGroupCreateMkStream(stream, group, start)
+}
+
+func (c *mockRedisClient) XGroupDelConsumer(stream, group, consumer string) (int64, error) {
+	if c.XGroupDelConsumerMock != nil {
+		return c.XGroupDelConsumerMock(stream, group, consumer)
+	}
+	return c.client.XGroupDelConsumer(stream, group, consumer)
+}
+
+func (c *mockRedisClient) XReadGroup(a *redis.XReadGroupArgs) ([]redis.XStream, error) {
+	if c.XReadGroupMock != nil {
+		return c.XReadGroupMock(a)
+	}
+	return c.client.XReadGroup(a)
+}
+
+func (c *mockRedisClient) XAck(stream, group string, ids ...string) (int64, error) {
+	if c.XAckMock != nil {
+		return c.XAckMock(stream, group, ids...)
+	}
+	return c.client.XAck(stream, group, ids...)
+}
+
+func (c *mockRedisClient) XPendingExt(a *redis.XPendingExtArgs) ([]redis.XPendingExt, error) {
+	if c.XPendingExtMock != nil {
+		return c.XPendingExtMock(a)
+	}
+	return c.client.XPendingExt(a)
+}
+
+func (c *mockRedisClient) XClaim(a *redis.XClaimArgs) ([]redis.XMessage, error) {
+	if c.XClaimMock != nil {
+		return c.XClaimMock(a)
+	}
+	return c.client.XClaim(a)
+}
+
+func (c *mockRedisClient) Pipelined(fn func(redis.Pipeliner) error) ([]redis.Cmder, error) {
+	if c.PipelinedMock != nil {
+		return c.PipelinedMock(fn)
+	}
+	return c.client.Pipelined(fn)
+}
+
+func (c *mockRedisClient) TxPipelined(fn func(redis.Pipeliner) error) ([]redis.Cmder, error) {
+	if c.TxPipelinedMock != nil {
+		return c.TxPipelinedMock(fn)
+	}
+	return c.client.TxPipelined(fn)
+}
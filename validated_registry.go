@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/bsm/redislock"
 )
@@ -37,17 +38,24 @@ type validatedRegistry struct {
 	rabbitMQRouterConfigs   map[string]*RabbitMQRouterConfig
 	lockServers             map[string]string
 	enums                   map[string]Enum
+
+	localCacheInvalidationInstance string
+	stampedeLockTTL                time.Duration
+	cacheCodec                     *cacheCodecConfig
+	queueProvider                  QueueProvider
+	queues                         map[string]int
+	loadByIDGroup                  singleFlight
 }
 
 func (r *validatedRegistry) CreateEngine() *Engine {
-	e := &Engine{registry: r}
+	e := &Engine{registry: r, trackLimit: defaultTrackLimit}
 	e.dataDog = &dataDog{engine: e}
 	e.dbs = make(map[string]*DB)
 	e.trackedEntities = make([]Entity, 0)
 	if e.registry.sqlClients != nil {
 		for key, val := range e.registry.sqlClients {
 			e.dbs[key] = &DB{engine: e, code: val.code, databaseName: val.databaseName,
-				client: &standardSQLClient{db: val.db}}
+				client: &standardSQLClient{db: val.db}, maxAllowedPacket: val.maxAllowedPacket}
 		}
 	}
 	if e.registry.clickHouseClients != nil {
@@ -59,13 +67,13 @@ func (r *validatedRegistry) CreateEngine() *Engine {
 	e.localCache = make(map[string]*LocalCache)
 	if e.registry.localCacheContainers != nil {
 		for key, val := range e.registry.localCacheContainers {
-			e.localCache[key] = &LocalCache{engine: e, code: val.code, lru: val.lru, ttl: val.ttl}
+			e.localCache[key] = &LocalCache{engine: e, code: val.code, storage: val.storage, ttl: val.ttl, group: &val.group}
 		}
 	}
 	e.redis = make(map[string]*RedisCache)
 	if e.registry.redisServers != nil {
 		for key, val := range e.registry.redisServers {
-			e.redis[key] = &RedisCache{engine: e, code: val.code, client: &standardRedisClient{val.client, val.ring}}
+			e.redis[key] = &RedisCache{engine: e, code: val.code, client: &standardRedisClient{client: val.client, cluster: val.cluster}, group: &val.group}
 		}
 	}
 	e.elastic = make(map[string]*Elastic)
@@ -0,0 +1,279 @@
+package orm
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type VerifyCacheOptions struct {
+	BatchSize     int
+	Repair        bool
+	CachedIndexes bool
+	Pause         time.Duration
+	OnMismatch    func(mismatch *CacheMismatch)
+}
+
+type CacheMismatch struct {
+	Pool      string
+	Key       string
+	ID        uint64
+	IndexName string
+	Cached    interface{}
+	Expected  interface{}
+}
+
+func verifyCache(engine *Engine, entity Entity, where *Where, options *VerifyCacheOptions) (mismatches []*CacheMismatch) {
+	schema := initIfNeeded(engine, entity).tableSchema
+	if options == nil {
+		options = &VerifyCacheOptions{}
+	}
+	localCache, hasLocalCache := schema.GetLocalCache(engine)
+	redisCache, hasRedis := schema.GetRedisCache(engine)
+	if !hasLocalCache && !hasRedis {
+		return nil
+	}
+	mismatches = make([]*CacheMismatch, 0)
+	report := func(mismatch *CacheMismatch) {
+		mismatches = append(mismatches, mismatch)
+		if options.OnMismatch != nil {
+			options.OnMismatch(mismatch)
+		}
+		if options.Repair {
+			if hasLocalCache && mismatch.Pool == localCache.code {
+				localCache.Remove(mismatch.Key)
+				engine.publishLocalCacheInvalidation(map[string][]string{localCache.code: {mismatch.Key}})
+			}
+			if hasRedis && mismatch.Pool == redisCache.code {
+				redisCache.Del(mismatch.Key)
+			}
+		}
+	}
+	batchSize := options.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultWarmUpCacheBatchSize
+	}
+	indexes := make(map[string]warmUpCachedIndexArguments)
+	buckets := make(map[string]map[string][]interface{})
+	lastID := uint64(0)
+	walkEntityTable(engine, schema, where, batchSize, options.Pause, func(entities []Entity) {
+		keys := make([]string, len(entities))
+		expected := make(map[string]Entity, len(entities))
+		gaps := make([]string, 0)
+		for i, e := range entities {
+			keys[i] = schema.getCacheKey(e.GetID())
+			expected[keys[i]] = e
+			if options.CachedIndexes {
+				addWarmUpCachedIndexArguments(schema, indexes, e.getORM().dBData)
+				addVerifyCachedIndexBuckets(schema, buckets, e.getORM().dBData)
+			}
+			if e.GetID()-lastID-1 <= uint64(batchSize) {
+				for id := lastID + 1; id < e.GetID(); id++ {
+					gaps = append(gaps, schema.getCacheKey(id))
+				}
+			}
+			lastID = e.GetID()
+		}
+		if hasLocalCache {
+			for key, cached := range localCache.MGet(keys...) {
+				if cached == nil {
+					continue
+				}
+				value := buildLocalCacheValue(expected[key])
+				if !reflect.DeepEqual(cached, value) {
+					report(&CacheMismatch{Pool: localCache.code, Key: key, ID: expected[key].GetID(), Cached: cached, Expected: value})
+				}
+			}
+			if len(gaps) > 0 {
+				verifyMissingIDs(engine, schema, localCache.code, localCache.MGet(gaps...), report)
+			}
+		}
+		if hasRedis {
+			for key, cached := range redisCache.MGet(keys...) {
+				if cached == nil {
+					continue
+				}
+				value := buildLocalCacheValue(expected[key])
+				if !reflect.DeepEqual(decodeRedisValue(cached.(string)), value) {
+					report(&CacheMismatch{Pool: redisCache.code, Key: key, ID: expected[key].GetID(), Cached: cached, Expected: value})
+				}
+			}
+		}
+	}, nil)
+	if hasRedis {
+		match := schema.cachePrefix + ":" + schema.columnsStamp + ":*"
+		cursor := uint64(0)
+		for {
+			var keys []string
+			keys, cursor = redisCache.Scan(cursor, match, int64(batchSize))
+			if len(keys) > 0 {
+				verifyMissingIDs(engine, schema, redisCache.code, redisCache.MGet(keys...), report)
+			}
+			if cursor == 0 {
+				break
+			}
+		}
+	}
+	if options.CachedIndexes {
+		verifyCachedIndexes(engine, schema, indexes, buckets, report)
+	}
+	return mismatches
+}
+
+func verifyMissingIDs(engine *Engine, schema *tableSchema, pool string, cached map[string]interface{}, report func(mismatch *CacheMismatch)) {
+	ids := make([]uint64, 0, len(cached))
+	keys := make(map[uint64]string, len(cached))
+	for key, value := range cached {
+		if value == nil || value == "nil" {
+			continue
+		}
+		id, err := strconv.ParseUint(key[strings.LastIndex(key, ":")+1:], 10, 64)
+		if err == nil {
+			ids = append(ids, id)
+			keys[id] = key
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+	found, _ := searchIDs(false, engine, NewWhere("`ID` IN ?", ids), &Pager{CurrentPage: 1, PageSize: len(ids)}, false, schema.t)
+	for _, id := range found {
+		delete(keys, id)
+	}
+	for id, key := range keys {
+		report(&CacheMismatch{Pool: pool, Key: key, ID: id, Cached: cached[key]})
+	}
+}
+
+func addVerifyCachedIndexBuckets(schema *tableSchema, buckets map[string]map[string][]interface{}, data map[string]interface{}) {
+	for indexName, definition := range schema.cachedIndexesAll {
+		if !definition.Ranges {
+			continue
+		}
+		bucket := make([]interface{}, len(definition.BucketFields))
+		for i, field := range definition.BucketFields {
+			bucket[i] = data[field]
+			if bucket[i] == nil {
+				bucket = nil
+				break
+			}
+		}
+		if bucket == nil {
+			continue
+		}
+		if buckets[indexName] == nil {
+			buckets[indexName] = make(map[string][]interface{})
+		}
+		buckets[indexName][getCacheKeySearch(schema, indexName, bucket...)] = bucket
+	}
+}
+
+func verifyCachedIndexes(engine *Engine, schema *tableSchema, indexes map[string]warmUpCachedIndexArguments,
+	buckets map[string]map[string][]interface{}, report func(mismatch *CacheMismatch)) {
+	for indexName, allArguments := range indexes {
+		for key, arguments := range allArguments {
+			verifyCachedIndex(engine, schema, indexName, key, arguments, report)
+		}
+	}
+	for indexName, keys := range buckets {
+		for key, bucket := range keys {
+			verifyCachedRangeIndex(engine, schema, indexName, key, bucket, report)
+		}
+	}
+}
+
+// verifyCachedRangeIndex checks only ids stored in pages of range queries, arguments of these queries are not known
+func verifyCachedRangeIndex(engine *Engine, schema *tableSchema, indexName string, key string, bucket []interface{},
+	report func(mismatch *CacheMismatch)) {
+	definition := schema.cachedIndexesAll[indexName]
+	cached := getCachedIndexFields(engine, schema, key)
+	ids := make([]uint64, 0)
+	for _, fields := range cached {
+		for _, value := range fields {
+			for i, id := range strings.Split(fmt.Sprintf("%v", value), " ") {
+				parsed, err := strconv.ParseUint(id, 10, 64)
+				if i > 0 && err == nil && parsed > 0 {
+					ids = append(ids, parsed)
+				}
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+	query := "`ID` IN ?"
+	for _, field := range definition.BucketFields {
+		query += " AND `" + field + "` = ?"
+	}
+	where := NewWhere(query, append([]interface{}{ids}, bucket...)...)
+	found, _ := searchIDs(false, engine, where, &Pager{CurrentPage: 1, PageSize: len(ids)}, false, schema.t)
+	valid := make(map[string]bool, len(found))
+	for _, id := range found {
+		valid[strconv.FormatUint(id, 10)] = true
+	}
+	for pool, fields := range cached {
+		for _, value := range fields {
+			for i, id := range strings.Split(fmt.Sprintf("%v", value), " ") {
+				if i > 0 && id != "0" && !valid[id] {
+					report(&CacheMismatch{Pool: pool, Key: key, IndexName: indexName, Cached: value})
+					break
+				}
+			}
+		}
+	}
+}
+
+func getCachedIndexFields(engine *Engine, schema *tableSchema, key string) map[string]map[string]interface{} {
+	cached := make(map[string]map[string]interface{})
+	if localCache, hasLocalCache := schema.GetLocalCache(engine); hasLocalCache {
+		cached[localCache.code] = localCache.HGetAll(key)
+	}
+	if redisCache, hasRedis := schema.GetRedisCache(engine); hasRedis {
+		cached[redisCache.code] = make(map[string]interface{})
+		for field, value := range redisCache.HGetAll(key) {
+			cached[redisCache.code][field] = value
+		}
+	}
+	return cached
+}
+
+func verifyCachedIndex(engine *Engine, schema *tableSchema, indexName string, key string, arguments []interface{},
+	report func(mismatch *CacheMismatch)) {
+	definition := schema.cachedIndexesAll[indexName]
+	_, isOne := schema.cachedIndexesOne[indexName]
+	cached := getCachedIndexFields(engine, schema, key)
+	expected := make(map[string]string)
+	for pool, fields := range cached {
+		for field, value := range fields {
+			pageValue, has := expected[field]
+			if !has {
+				pageValue = getCachedIndexPageValue(engine, schema, definition, isOne, arguments, field)
+				expected[field] = pageValue
+			}
+			if fmt.Sprintf("%v", value) != pageValue {
+				report(&CacheMismatch{Pool: pool, Key: key, IndexName: indexName, Cached: value, Expected: pageValue})
+			}
+		}
+	}
+}
+
+func getCachedIndexPageValue(engine *Engine, schema *tableSchema, definition *cachedQueryDefinition, isOne bool,
+	arguments []interface{}, page string) string {
+	where := NewWhere(definition.Query, arguments...)
+	if isOne {
+		results, _ := searchIDs(true, engine, where, &Pager{CurrentPage: 1, PageSize: 1}, false, schema.t)
+		value := fmt.Sprintf("%d", len(results))
+		if len(results) > 0 {
+			value += fmt.Sprintf(" %d", results[0])
+		}
+		return value
+	}
+	pageNumber, err := strconv.Atoi(page)
+	if err != nil || pageNumber < 1 {
+		return ""
+	}
+	results, total := searchIDsWithCount(true, engine, where, &Pager{CurrentPage: pageNumber, PageSize: idsOnCachePage}, schema.t)
+	return strings.Trim(fmt.Sprintf("%v", append([]uint64{uint64(total)}, results...)), "[]")
+}
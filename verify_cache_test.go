@@ -0,0 +1,110 @@
+package orm
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testEntityVerifyCache struct {
+	ORM      `orm:"localCache;redisCache"`
+	ID       uint         `orm:"index=AgeIndex:2"`
+	Name     string       `orm:"length=100"`
+	Age      uint16       `orm:"index=AgeIndex"`
+	IndexAge *CachedQuery `query:":Age = ? ORDER BY :ID"`
+}
+
+func TestVerifyCache(t *testing.T) {
+	var entity testEntityVerifyCache
+	engine := PrepareTables(t, &Registry{}, entity)
+	for i := 1; i <= 3; i++ {
+		engine.Track(&testEntityVerifyCache{Name: "Name " + strconv.Itoa(i), Age: 1})
+	}
+	engine.Flush()
+	var rows []*testEntityVerifyCache
+	engine.LoadByIDs([]uint64{1, 2, 3}, &rows)
+	totalRows := engine.CachedSearch(&rows, "IndexAge", nil, 1)
+	assert.Equal(t, 3, totalRows)
+
+	options := &VerifyCacheOptions{CachedIndexes: true}
+	mismatches := engine.VerifyCache(&entity, nil, options)
+	assert.Len(t, mismatches, 0)
+
+	engine.GetMysql().Exec("UPDATE `testEntityVerifyCache` SET `Name` = 'Name 3.1', `Age` = 2 WHERE `ID` = 3")
+	reported := 0
+	options.OnMismatch = func(mismatch *CacheMismatch) {
+		reported++
+	}
+	mismatches = engine.VerifyCache(&entity, nil, options)
+	assert.Len(t, mismatches, 4)
+	assert.Equal(t, 4, reported)
+	for _, mismatch := range mismatches {
+		if mismatch.IndexName == "" {
+			assert.Equal(t, uint64(3), mismatch.ID)
+		} else {
+			assert.Equal(t, "IndexAge", mismatch.IndexName)
+			assert.Equal(t, "2 1 2", mismatch.Expected)
+		}
+	}
+
+	options.Repair = true
+	mismatches = engine.VerifyCache(&entity, NewWhere("`Age` = ?", 2), options)
+	assert.Len(t, mismatches, 2)
+	mismatches = engine.VerifyCache(&entity, nil, options)
+	assert.Len(t, mismatches, 2)
+	mismatches = engine.VerifyCache(&entity, nil, options)
+	assert.Len(t, mismatches, 0)
+
+	found := engine.LoadByID(3, &entity)
+	assert.True(t, found)
+	assert.Equal(t, "Name 3.1", entity.Name)
+	totalRows = engine.CachedSearch(&rows, "IndexAge", nil, 1)
+	assert.Equal(t, 2, totalRows)
+}
+
+type testEntityVerifyCacheRange struct {
+	ORM      `orm:"localCache;redisCache"`
+	ID       uint
+	Age      uint16
+	IndexAge *CachedQuery `query:":Age >= ? ORDER BY :ID"`
+}
+
+func TestVerifyCacheMissingRowsAndRanges(t *testing.T) {
+	var entity testEntityVerifyCacheRange
+	engine := PrepareTables(t, &Registry{}, entity)
+	for i := 1; i <= 3; i++ {
+		engine.Track(&testEntityVerifyCacheRange{Age: uint16(i)})
+	}
+	engine.Flush()
+	var rows []*testEntityVerifyCacheRange
+	engine.LoadByIDs([]uint64{1, 2, 3}, &rows)
+	totalRows := engine.CachedSearch(&rows, "IndexAge", nil, 2)
+	assert.Equal(t, 2, totalRows)
+
+	options := &VerifyCacheOptions{CachedIndexes: true}
+	mismatches := engine.VerifyCache(&entity, nil, options)
+	assert.Len(t, mismatches, 0)
+
+	engine.GetMysql().Exec("DELETE FROM `testEntityVerifyCacheRange` WHERE `ID` = 2")
+	mismatches = engine.VerifyCache(&entity, nil, options)
+	assert.Len(t, mismatches, 4)
+	for _, mismatch := range mismatches {
+		if mismatch.IndexName == "" {
+			assert.Equal(t, uint64(2), mismatch.ID)
+			assert.Nil(t, mismatch.Expected)
+		} else {
+			assert.Equal(t, "IndexAge", mismatch.IndexName)
+			assert.Equal(t, "2 2 3", mismatch.Cached)
+			assert.Nil(t, mismatch.Expected)
+		}
+	}
+
+	options.Repair = true
+	mismatches = engine.VerifyCache(&entity, nil, options)
+	assert.Len(t, mismatches, 4)
+	mismatches = engine.VerifyCache(&entity, nil, options)
+	assert.Len(t, mismatches, 0)
+	totalRows = engine.CachedSearch(&rows, "IndexAge", nil, 2)
+	assert.Equal(t, 1, totalRows)
+}
@@ -0,0 +1,169 @@
+package orm
+
+import (
+	"reflect"
+	"time"
+)
+
+const defaultWarmUpCacheBatchSize = 1000
+
+type WarmUpCacheOptions struct {
+	BatchSize int
+	// CachedIndexes fills all pages of cached queries, queries with IN and range conditions are skipped
+	CachedIndexes bool
+	Pause         time.Duration
+	Progress      func(total int, lastID uint64)
+}
+
+type warmUpCachedIndexArguments map[string][]interface{}
+
+func warmUpCache(engine *Engine, entity Entity, where *Where, options *WarmUpCacheOptions) (total int) {
+	schema := initIfNeeded(engine, entity).tableSchema
+	if options == nil {
+		options = &WarmUpCacheOptions{}
+	}
+	localCache, hasLocalCache := schema.GetLocalCache(engine)
+	redisCache, hasRedis := schema.GetRedisCache(engine)
+	if !hasLocalCache && !hasRedis {
+		return 0
+	}
+	total = walkEntityTable(engine, schema, where, options.BatchSize, options.Pause, func(entities []Entity) {
+		keys := make([]string, len(entities))
+		indexes := make(map[string]warmUpCachedIndexArguments)
+		for i, e := range entities {
+			keys[i] = schema.getCacheKey(e.GetID())
+			if options.CachedIndexes {
+				addWarmUpCachedIndexArguments(schema, indexes, e.getORM().dBData)
+			}
+		}
+		// keys already in cache are not overwritten, they could be set by flush after these rows were loaded
+		if hasLocalCache {
+			cached := localCache.MGet(keys...)
+			localPairs := make([]interface{}, 0, len(entities)*2)
+			for i, e := range entities {
+				if cached[keys[i]] == nil {
+					localPairs = append(localPairs, keys[i], schema.localCacheValue(buildLocalCacheValue(e)))
+				}
+			}
+			if len(localPairs) > 0 {
+				localCache.MSet(localPairs...)
+			}
+		}
+		if hasRedis {
+			redisCache.Pipeline(func(pipeline *RedisPipeline) {
+				for i, e := range entities {
+					pipeline.SetNX(keys[i], buildRedisValue(engine, e), schema.redisTTL)
+				}
+			})
+		}
+		if options.CachedIndexes {
+			warmUpCachedIndexes(engine, schema, indexes, options.Pause)
+		}
+	}, options.Progress)
+	return total
+}
+
+func walkEntityTable(engine *Engine, schema *tableSchema, where *Where, batchSize int, pause time.Duration,
+	handler func(entities []Entity), progress func(total int, lastID uint64)) (total int) {
+	if batchSize <= 0 {
+		batchSize = defaultWarmUpCacheBatchSize
+	}
+	query := "`ID` > ?"
+	var parameters []interface{}
+	if where != nil {
+		query += " AND (" + where.String() + ")"
+		parameters = where.GetParameters()
+	}
+	query += " ORDER BY `ID`"
+	lastID := uint64(0)
+	for {
+		rows := reflect.New(reflect.SliceOf(reflect.PtrTo(schema.t))).Elem()
+		batchWhere := NewWhere(query, append([]interface{}{lastID}, parameters...)...)
+		_ = search(false, engine, batchWhere, &Pager{CurrentPage: 1, PageSize: batchSize}, false, rows)
+		l := rows.Len()
+		if l == 0 {
+			break
+		}
+		entities := make([]Entity, l)
+		for i := 0; i < l; i++ {
+			entities[i] = rows.Index(i).Interface().(Entity)
+		}
+		lastID = entities[l-1].GetID()
+		handler(entities)
+		total += l
+		if progress != nil {
+			progress(total, lastID)
+		}
+		if l < batchSize {
+			break
+		}
+		if pause > 0 {
+			time.Sleep(pause)
+		}
+	}
+	return total
+}
+
+func addWarmUpCachedIndexArguments(schema *tableSchema, indexes map[string]warmUpCachedIndexArguments, data map[string]interface{}) {
+	for indexName, definition := range schema.cachedIndexesAll {
+		if definition.Ranges {
+			continue
+		}
+		arguments := make([]interface{}, 0, len(definition.QueryFields))
+		valid := true
+		for _, field := range definition.QueryFields {
+			if schema.hasFakeDelete && field == "FakeDelete" {
+				continue
+			}
+			value := data[field]
+			if value == nil {
+				valid = false
+				break
+			}
+			arguments = append(arguments, value)
+		}
+		if !valid {
+			continue
+		}
+		if indexes[indexName] == nil {
+			indexes[indexName] = make(warmUpCachedIndexArguments)
+		}
+		indexes[indexName][getCacheKeySearch(schema, indexName, arguments...)] = arguments
+	}
+}
+
+func warmUpCachedIndexes(engine *Engine, schema *tableSchema, indexes map[string]warmUpCachedIndexArguments, pause time.Duration) {
+	for indexName, allArguments := range indexes {
+		definition := schema.cachedIndexesAll[indexName]
+		_, isOne := schema.cachedIndexesOne[indexName]
+		i := 0
+		for _, arguments := range allArguments {
+			if isOne {
+				cachedSearchOne(engine, reflect.New(schema.t).Interface().(Entity), indexName, arguments, nil)
+			} else {
+				warmUpCachedIndexPages(engine, schema, definition, indexName, arguments)
+			}
+			i++
+			if pause > 0 && i%defaultWarmUpCacheBatchSize == 0 {
+				time.Sleep(pause)
+			}
+		}
+	}
+}
+
+func warmUpCachedIndexPages(engine *Engine, schema *tableSchema, definition *cachedQueryDefinition, indexName string,
+	arguments []interface{}) {
+	total := -1
+	for page := 1; ; page++ {
+		start := (page - 1) * idsOnCachePage
+		if start >= definition.Max || (total >= 0 && start >= total) {
+			return
+		}
+		pager := &Pager{CurrentPage: page, PageSize: idsOnCachePage}
+		if start+idsOnCachePage > definition.Max {
+			pager = &Pager{CurrentPage: start + 1, PageSize: 1}
+		}
+		entities := reflect.New(reflect.SliceOf(reflect.PtrTo(schema.t)))
+		total = cachedSearch(engine, entities.Interface(), indexName, pager, arguments, nil)
+	}
+}
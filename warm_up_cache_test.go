@@ -0,0 +1,55 @@
+package orm
+
+import (
+	"strconv"
+	"testing"
+
+	log2 "github.com/apex/log"
+
+	"github.com/apex/log/handlers/memory"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testEntityWarmUpCache struct {
+	ORM       `orm:"redisCache"`
+	ID        uint         `orm:"index=AgeIndex:2"`
+	Name      string       `orm:"length=100;unique=NameIndex"`
+	Age       uint16       `orm:"index=AgeIndex"`
+	IndexAge  *CachedQuery `query:":Age = ? ORDER BY :ID"`
+	IndexName *CachedQuery `queryOne:":Name = ?"`
+}
+
+func TestWarmUpCache(t *testing.T) {
+	var entity testEntityWarmUpCache
+	engine := PrepareTables(t, &Registry{}, entity)
+	for i := 1; i <= 10; i++ {
+		engine.Track(&testEntityWarmUpCache{Name: "Name " + strconv.Itoa(i), Age: uint16(i % 2)})
+	}
+	engine.Flush()
+	engine.GetRedis().FlushDB()
+
+	progress := make([]int, 0)
+	options := &WarmUpCacheOptions{BatchSize: 4, CachedIndexes: true, Progress: func(total int, lastID uint64) {
+		progress = append(progress, total)
+	}}
+	total := engine.WarmUpCache(&entity, NewWhere("`ID` <= ?", 9), options)
+	assert.Equal(t, 9, total)
+	assert.Equal(t, []int{4, 8, 9}, progress)
+
+	DBLogger := memory.New()
+	engine.AddQueryLogger(DBLogger, log2.InfoLevel, QueryLoggerSourceDB)
+	found := engine.LoadByID(9, &entity)
+	assert.True(t, found)
+	assert.Equal(t, "Name 9", entity.Name)
+	var rows []*testEntityWarmUpCache
+	totalRows := engine.CachedSearch(&rows, "IndexAge", nil, 1)
+	assert.Equal(t, 5, totalRows)
+	found = engine.CachedSearchOne(&entity, "IndexName", "Name 2")
+	assert.True(t, found)
+	assert.Len(t, DBLogger.Entries, 0)
+
+	found = engine.LoadByID(10, &entity)
+	assert.True(t, found)
+	assert.Len(t, DBLogger.Entries, 1)
+}
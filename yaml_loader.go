@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/juju/errors"
 )
@@ -53,8 +54,19 @@ func InitByYaml(yaml map[string]interface{}) (registry *Registry) {
 					registry.RegisterDirtyQueue(k.(string), asInt)
 				}
 			case "local_cache":
-				number := validateOrmInt(value, key)
-				registry.RegisterLocalCache(number, key)
+				def, ok := value.(map[interface{}]interface{})
+				if ok {
+					size := validateOrmInt(def["size"], key)
+					ttl := 0
+					_, hasTTL := def["ttl"]
+					if hasTTL {
+						ttl = validateOrmInt(def["ttl"], key)
+					}
+					registry.RegisterLocalCacheWithTTL(size, ttl, key)
+				} else {
+					number := validateOrmInt(value, key)
+					registry.RegisterLocalCache(number, key)
+				}
 			}
 		}
 	}
@@ -86,6 +98,20 @@ func validateClickHouseURI(registry *Registry, value interface{}, key string) {
 }
 
 func validateRedisURI(registry *Registry, value interface{}, key string) {
+	asMap, ok := value.(map[interface{}]interface{})
+	if ok {
+		sentinel, has := asMap["sentinel"]
+		if has {
+			validateRedisSentinel(registry, sentinel, key)
+			return
+		}
+		cluster, has := asMap["cluster"]
+		if !has {
+			panic(errors.NotValidf("redis definition: %v", value))
+		}
+		registry.RegisterRedisCluster(validateRedisAddresses(cluster, key), key)
+		return
+	}
 	asString, ok := value.(string)
 	if !ok {
 		asStrings, ok := value.([]interface{})
@@ -124,6 +150,35 @@ func validateRedisURI(registry *Registry, value interface{}, key string) {
 	registry.RegisterRedis(uri, int(db), key)
 }
 
+func validateRedisSentinel(registry *Registry, value interface{}, key string) {
+	def, ok := value.(map[interface{}]interface{})
+	if !ok {
+		panic(errors.NotValidf("redis sentinel definition: %v", value))
+	}
+	master, has := def["master"]
+	if !has {
+		panic(errors.NotFoundf("redis sentinel master name: %s", key))
+	}
+	db := 0
+	_, has = def["db"]
+	if has {
+		db = validateOrmInt(def["db"], key)
+	}
+	registry.RegisterRedisSentinel(validateOrmString(master, key), validateRedisAddresses(def["addresses"], key), db, key)
+}
+
+func validateRedisAddresses(value interface{}, key string) []string {
+	addresses, ok := value.([]interface{})
+	if !ok || len(addresses) == 0 {
+		panic(errors.NotValidf("redis addresses: %v", value))
+	}
+	uris := make([]string, len(addresses))
+	for i, address := range addresses {
+		uris[i] = validateOrmString(address, key)
+	}
+	return uris
+}
+
 func getBoolOptional(data map[interface{}]interface{}, key string, defaultValue bool) bool {
 	val, has := data[key]
 	if !has {
@@ -145,7 +200,12 @@ func validateOrmRabbitMQ(registry *Registry, value interface{}, key string) {
 	if !ok {
 		panic(errors.NotValidf("rabbitMQ server definition: %s", key))
 	}
-	registry.RegisterRabbitMQServer(poolName, key)
+	if getBoolOptional(def, "confirm", false) {
+		confirmTimeout, _ := strconv.ParseInt(fmt.Sprintf("%v", def["confirmTimeout"]), 10, 64)
+		registry.RegisterRabbitMQServerWithConfirms(poolName, time.Duration(confirmTimeout)*time.Second, key)
+	} else {
+		registry.RegisterRabbitMQServer(poolName, key)
+	}
 	value, has = def["queues"]
 	if has {
 		asSlice, ok := value.([]interface{})
@@ -193,8 +253,14 @@ func validateOrmRabbitMQ(registry *Registry, value interface{}, key string) {
 				}
 			}
 			prefetchCount, _ := strconv.ParseInt(fmt.Sprintf("%v", asMap["prefetchCount"]), 10, 64)
+			maxRetries, _ := strconv.ParseInt(fmt.Sprintf("%v", asMap["maxRetries"]), 10, 64)
+			retryDelay, _ := strconv.ParseInt(fmt.Sprintf("%v", asMap["retryDelay"]), 10, 64)
+			deadLetter := getBoolOptional(asMap, "deadLetter", false)
+			confirm := getBoolOptional(asMap, "confirm", false)
+			maxPriority, _ := strconv.ParseUint(fmt.Sprintf("%v", asMap["maxPriority"]), 10, 8)
 			config := &RabbitMQQueueConfig{asString, int(prefetchCount), delayed, router, durable,
-				routerKeys, autoDeleted}
+				routerKeys, autoDeleted, int(maxRetries), time.Duration(retryDelay) * time.Second, deadLetter, confirm,
+				uint8(maxPriority)}
 			registry.RegisterRabbitMQQueue(config, key)
 		}
 	}
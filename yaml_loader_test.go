@@ -16,6 +16,9 @@ func TestYamlLoader(t *testing.T) {
 	assert.Nil(t, err)
 	registry := InitByYaml(data)
 	assert.NotNil(t, registry)
+	assert.NotNil(t, registry.redisServers["cluster_pool"].cluster)
+	assert.NotNil(t, registry.redisServers["sentinel_pool"].client)
+	assert.Nil(t, registry.redisServers["sentinel_pool"].cluster)
 	validatedRegistry, err := registry.Validate()
 	assert.NoError(t, err)
 	assert.NotNil(t, validatedRegistry)